@@ -0,0 +1,75 @@
+package cycle
+
+import "fmt"
+
+// Cycle describes a sequence of states which eventually loops. The states at steps
+// `Start` and `Start+Length` have the same key, so every step after `Start` repeats
+// with a period of `Length`
+type Cycle struct {
+	Start  int
+	Length int
+
+	// measurements[i] is the measurement taken after i steps, up to and including
+	// the first repeated state at step Start+Length
+	measurements []int
+}
+
+// Find repeatedly applies `step` to `initial` until it reaches a state whose `key` has
+// already been seen. `measure` is recorded after every step, so the cycle can later be
+// used to extrapolate the measurement to any number of steps.
+//
+// `step` may mutate and return the same state, since only keys and measurements are kept.
+// If no repeat is found within `maxSteps` steps, an error is returned; a non-positive
+// `maxSteps` means there is no limit
+func Find[S any, K comparable](
+	initial S,
+	step func(S) S,
+	key func(S) K,
+	measure func(S) int,
+	maxSteps int,
+) (*Cycle, error) {
+	seen := map[K]int{}
+	state := initial
+	measurements := []int{}
+
+	for i := 0; maxSteps <= 0 || i <= maxSteps; i++ {
+		measurements = append(measurements, measure(state))
+		k := key(state)
+		if start, ok := seen[k]; ok {
+			return &Cycle{Start: start, Length: i - start, measurements: measurements}, nil
+		}
+		seen[k] = i
+		state = step(state)
+	}
+
+	return nil, fmt.Errorf("no cycle found within %d steps", maxSteps)
+}
+
+// At returns the measurement after `n` steps. Steps that were simulated are returned
+// directly; later steps are extrapolated by assuming every loop adds the same amount
+// to the measurement as the first loop did
+func (c *Cycle) At(n int) int {
+	if n < 0 {
+		panic(fmt.Sprintf("negative step %d", n))
+	}
+	if n < len(c.measurements) {
+		return c.measurements[n]
+	}
+
+	loops, offset := (n-c.Start)/c.Length, (n-c.Start)%c.Length
+	return c.measurements[c.Start+offset] + loops*c.LoopDelta()
+}
+
+// LoopDelta returns how much the measurement changes over a single loop
+func (c *Cycle) LoopDelta() int {
+	return c.measurements[c.Start+c.Length] - c.measurements[c.Start]
+}
+
+// StepOf returns the step within the first loop that is equivalent to step `n`,
+// which is useful for looking up the state itself rather than the measurement
+func (c *Cycle) StepOf(n int) int {
+	if n < c.Start {
+		return n
+	}
+	return c.Start + (n-c.Start)%c.Length
+}
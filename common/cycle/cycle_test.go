@@ -0,0 +1,151 @@
+package cycle
+
+import "testing"
+
+// walker moves between positions following `next`, adding each position it
+// arrives at to its total
+type walker struct {
+	next       []int
+	pos, total int
+}
+
+func (w walker) step() walker {
+	w.pos = w.next[w.pos]
+	w.total += w.pos
+	return w
+}
+
+func findWalk(t *testing.T, next []int) *Cycle {
+	t.Helper()
+	c, err := Find(
+		walker{next: next},
+		walker.step,
+		func(w walker) int { return w.pos },
+		func(w walker) int { return w.total },
+		0,
+	)
+	if err != nil {
+		t.Fatalf("Find(%v) returned %v", next, err)
+	}
+	return c
+}
+
+func TestFind(t *testing.T) {
+	tests := []struct {
+		name          string
+		next          []int
+		start, length int
+		loopDelta     int
+	}{
+		// 0 -> 1 -> 2 -> 3 -> 4 -> 5 -> 2 -> ...
+		{"prefix before the loop", []int{1, 2, 3, 4, 5, 2}, 2, 4, 3 + 4 + 5 + 2},
+		// 0 -> 1 -> 2 -> 3 -> 3 -> ...
+		{"loop of length 1", []int{1, 2, 3, 3}, 3, 1, 3},
+		// 0 -> 1 -> 2 -> 0 -> ...
+		{"no prefix", []int{1, 2, 0}, 0, 3, 1 + 2 + 0},
+		// 0 -> 0 -> ...
+		{"fixed point", []int{0}, 0, 1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := findWalk(t, tt.next)
+			if c.Start != tt.start || c.Length != tt.length {
+				t.Errorf("Find = start %d, length %d, want start %d, length %d", c.Start, c.Length, tt.start, tt.length)
+			}
+			if got := c.LoopDelta(); got != tt.loopDelta {
+				t.Errorf("LoopDelta() = %d, want %d", got, tt.loopDelta)
+			}
+
+			// Well past the simulated steps, At and StepOf must agree with simulating directly
+			w := walker{next: tt.next}
+			for n := 0; n < 1000; n++ {
+				if got := c.At(n); got != w.total {
+					t.Fatalf("At(%d) = %d, want %d", n, got, w.total)
+				}
+				// Positions are the keys, so the equivalent step must be at the same position
+				s := walker{next: tt.next}
+				for i := 0; i < c.StepOf(n); i++ {
+					s = s.step()
+				}
+				if s.pos != w.pos {
+					t.Fatalf("StepOf(%d) = %d, which is at position %d, want %d", n, c.StepOf(n), s.pos, w.pos)
+				}
+				w = w.step()
+			}
+		})
+	}
+}
+
+func TestAtFarPastCycle(t *testing.T) {
+	tests := []struct {
+		name       string
+		next       []int
+		n          int
+		want, step int
+	}{
+		// (10^12 - 2) = 4*249999999999 + 2, so it's 2 steps into the loop after the
+		// prefix of 2 steps, where the total is 10
+		{"prefix before the loop", []int{1, 2, 3, 4, 5, 2}, 1e12, 10 + 249999999999*14, 4},
+		// Each step after reaching 3 adds 3
+		{"loop of length 1", []int{1, 2, 3, 3}, 1e15, 6 + (1e15-3)*3, 3},
+		{"step within the prefix", []int{1, 2, 3, 4, 5, 2}, 1, 1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := findWalk(t, tt.next)
+			if got := c.At(tt.n); got != tt.want {
+				t.Errorf("At(%d) = %d, want %d", tt.n, got, tt.want)
+			}
+			if got := c.StepOf(tt.n); got != tt.step {
+				t.Errorf("StepOf(%d) = %d, want %d", tt.n, got, tt.step)
+			}
+		})
+	}
+}
+
+func TestFindSharedState(t *testing.T) {
+	// `step` mutating and returning the same state is fine, as only keys are kept
+	type counter struct{ n int }
+	c, err := Find(
+		&counter{},
+		func(s *counter) *counter { s.n++; return s },
+		func(s *counter) int { return s.n % 5 },
+		func(s *counter) int { return s.n },
+		0,
+	)
+	if err != nil {
+		t.Fatalf("Find returned %v", err)
+	}
+	if c.Start != 0 || c.Length != 5 || c.At(1e9) != 1e9 {
+		t.Errorf("Find = start %d, length %d, At(1e9) = %d, want 0, 5, 1e9", c.Start, c.Length, c.At(1e9))
+	}
+}
+
+func TestFindMaxSteps(t *testing.T) {
+	count := func(s int) int { return s + 1 }
+	id := func(s int) int { return s }
+	if _, err := Find(0, count, id, id, 100); err == nil {
+		t.Error("Find on a sequence that never repeats didn't return an error")
+	}
+
+	// Steps 0 to 3 are all distinct, and step 4 repeats step 0
+	mod4 := func(s int) int { return s % 4 }
+	if _, err := Find(0, count, mod4, id, 4); err != nil {
+		t.Errorf("Find with a repeat at exactly maxSteps returned %v", err)
+	}
+	if _, err := Find(0, count, mod4, id, 3); err == nil {
+		t.Error("Find with a repeat after maxSteps didn't return an error")
+	}
+}
+
+func TestAtNegativePanics(t *testing.T) {
+	c := findWalk(t, []int{0})
+	defer func() {
+		if recover() == nil {
+			t.Error("At(-1) didn't panic")
+		}
+	}()
+	c.At(-1)
+}
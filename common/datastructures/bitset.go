@@ -0,0 +1,324 @@
+package datastructures
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+const wordSize = 64
+
+// BitSet is a set of non-negative integers, stored as one bit per integer. A growable
+// bitset expands as needed when bits are set, while a fixed one panics if a bit outside
+// of its size is set. The zero value is an empty, growable bitset
+type BitSet struct {
+	words []uint64
+	size  int // Number of usable bits for fixed bitsets; unused for growable ones
+	fixed bool
+}
+
+// NewBitSet creates a growable bitset, with room for `size` bits before it needs to grow
+func NewBitSet(size int) *BitSet {
+	return &BitSet{words: make([]uint64, wordsFor(size))}
+}
+
+// NewFixedBitSet creates a bitset that can only hold the integers in [0, size)
+func NewFixedBitSet(size int) *BitSet {
+	return &BitSet{words: make([]uint64, wordsFor(size)), size: size, fixed: true}
+}
+
+// BitSetFromUint64 creates a growable bitset from the bits of `mask`
+func BitSetFromUint64(mask uint64) *BitSet {
+	return &BitSet{words: []uint64{mask}}
+}
+
+func wordsFor(size int) int {
+	return (size + wordSize - 1) / wordSize
+}
+
+// Cap returns how many bits the bitset can hold without growing
+func (b *BitSet) Cap() int {
+	if b.fixed {
+		return b.size
+	}
+	return len(b.words) * wordSize
+}
+
+func (b *BitSet) checkIndex(i int) {
+	if i < 0 || (b.fixed && i >= b.size) {
+		panic(fmt.Sprintf("bit %d out of range for bitset of size %d", i, b.Cap()))
+	}
+}
+
+// Set adds `i` to the set
+func (b *BitSet) Set(i int) {
+	b.checkIndex(i)
+	if w := i / wordSize; w >= len(b.words) {
+		b.words = append(b.words, make([]uint64, w-len(b.words)+1)...)
+	}
+	b.words[i/wordSize] |= 1 << (i % wordSize)
+}
+
+// Clear removes `i` from the set
+func (b *BitSet) Clear(i int) {
+	b.checkIndex(i)
+	if w := i / wordSize; w < len(b.words) {
+		b.words[w] &^= 1 << (i % wordSize)
+	}
+}
+
+// Test returns true if `i` is in the set
+func (b *BitSet) Test(i int) bool {
+	if i < 0 {
+		return false
+	}
+	w := i / wordSize
+	return w < len(b.words) && b.words[w]&(1<<(i%wordSize)) != 0
+}
+
+// Count returns the number of integers in the set
+func (b *BitSet) Count() int {
+	count := 0
+	for _, w := range b.words {
+		count += bits.OnesCount64(w)
+	}
+	return count
+}
+
+func (b *BitSet) IsEmpty() bool {
+	for _, w := range b.words {
+		if w != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Reset clears every bit, but keeps the allocated memory for reuse
+func (b *BitSet) Reset() {
+	for i := range b.words {
+		b.words[i] = 0
+	}
+}
+
+// NextSet returns the smallest integer in the set which is >= `i`, or false if there is none
+func (b *BitSet) NextSet(i int) (int, bool) {
+	if i < 0 {
+		i = 0
+	}
+	w := i / wordSize
+	if w >= len(b.words) {
+		return 0, false
+	}
+
+	// Mask off the bits below `i` in its word
+	word := b.words[w] >> (i % wordSize)
+	if word != 0 {
+		return i + bits.TrailingZeros64(word), true
+	}
+	for w++; w < len(b.words); w++ {
+		if b.words[w] != 0 {
+			return w*wordSize + bits.TrailingZeros64(b.words[w]), true
+		}
+	}
+	return 0, false
+}
+
+// Each calls `fn` with each integer in the set, in ascending order,
+// stopping early if `fn` returns false
+func (b *BitSet) Each(fn func(i int) bool) {
+	for w, word := range b.words {
+		for word != 0 {
+			bit := bits.TrailingZeros64(word)
+			if !fn(w*wordSize + bit) {
+				return
+			}
+			word &= word - 1 // Clear the lowest set bit
+		}
+	}
+}
+
+// Values returns the integers in the set, in ascending order
+func (b *BitSet) Values() []int {
+	vals := make([]int, 0, b.Count())
+	b.Each(func(i int) bool {
+		vals = append(vals, i)
+		return true
+	})
+	return vals
+}
+
+func (b *BitSet) Clone() *BitSet {
+	clone := *b
+	clone.words = make([]uint64, len(b.words))
+	copy(clone.words, b.words)
+	return &clone
+}
+
+// Equal returns true if both bitsets contain exactly the same integers
+func (b *BitSet) Equal(other *BitSet) bool {
+	long, short := b.words, other.words
+	if len(long) < len(short) {
+		long, short = short, long
+	}
+	for i, w := range long {
+		if i < len(short) && short[i] != w || i >= len(short) && w != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Last returns the largest integer in the set, or false if it's empty
+func (b *BitSet) Last() (int, bool) {
+	for i := len(b.words) - 1; i >= 0; i-- {
+		if b.words[i] != 0 {
+			return i*wordSize + bits.Len64(b.words[i]) - 1, true
+		}
+	}
+	return 0, false
+}
+
+// UnionWith adds every integer in `other` to `b`. Like `Set`, it panics if `b`
+// is fixed and `other` holds an integer outside of its size
+func (b *BitSet) UnionWith(other *BitSet) {
+	// Make sure `b` can hold the highest bit of `other` before merging
+	if last, ok := other.Last(); ok && (b.fixed || last/wordSize >= len(b.words)) {
+		b.Set(last)
+	}
+	for i := 0; i < len(other.words) && i < len(b.words); i++ {
+		b.words[i] |= other.words[i]
+	}
+}
+
+// IntersectWith removes every integer from `b` that isn't also in `other`
+func (b *BitSet) IntersectWith(other *BitSet) {
+	for i := range b.words {
+		if i < len(other.words) {
+			b.words[i] &= other.words[i]
+		} else {
+			b.words[i] = 0
+		}
+	}
+}
+
+// DifferenceWith removes every integer in `other` from `b`
+func (b *BitSet) DifferenceWith(other *BitSet) {
+	for i := 0; i < len(b.words) && i < len(other.words); i++ {
+		b.words[i] &^= other.words[i]
+	}
+}
+
+// Union returns a new bitset with the integers in `b` or `other`
+func (b *BitSet) Union(other *BitSet) *BitSet {
+	union := b.Clone()
+	union.UnionWith(other)
+	return union
+}
+
+// Intersection returns a new bitset with the integers in both `b` and `other`
+func (b *BitSet) Intersection(other *BitSet) *BitSet {
+	intersection := b.Clone()
+	intersection.IntersectWith(other)
+	return intersection
+}
+
+// Difference returns a new bitset with the integers in `b` but not `other`
+func (b *BitSet) Difference(other *BitSet) *BitSet {
+	difference := b.Clone()
+	difference.DifferenceWith(other)
+	return difference
+}
+
+// ShiftLeft returns a new bitset where every integer is increased by `n`. For fixed
+// bitsets, integers that would go past the size are dropped. A negative `n` shifts right
+func (b *BitSet) ShiftLeft(n int) *BitSet {
+	if n < 0 {
+		return b.ShiftRight(-n)
+	}
+
+	shifted := &BitSet{size: b.size, fixed: b.fixed}
+	b.Each(func(i int) bool {
+		if !b.fixed || i+n < b.size {
+			shifted.Set(i + n)
+		}
+		return true
+	})
+	if len(shifted.words) < len(b.words) {
+		shifted.words = append(shifted.words, make([]uint64, len(b.words)-len(shifted.words))...)
+	}
+	return shifted
+}
+
+// ShiftRight returns a new bitset where every integer is decreased by `n`.
+// Integers that would become negative are dropped. A negative `n` shifts left
+func (b *BitSet) ShiftRight(n int) *BitSet {
+	if n < 0 {
+		return b.ShiftLeft(-n)
+	}
+
+	shifted := &BitSet{words: make([]uint64, len(b.words)), size: b.size, fixed: b.fixed}
+	b.Each(func(i int) bool {
+		if i-n >= 0 {
+			shifted.Set(i - n)
+		}
+		return true
+	})
+	return shifted
+}
+
+// Uint64 returns the set as a single bitmask, which is a cheap map key. It returns
+// false if the set contains an integer that doesn't fit in 64 bits
+func (b *BitSet) Uint64() (uint64, bool) {
+	if len(b.words) == 0 {
+		return 0, true
+	}
+	for _, w := range b.words[1:] {
+		if w != 0 {
+			return 0, false
+		}
+	}
+	return b.words[0], true
+}
+
+// Key returns a string that uniquely identifies the integers in the set, so that
+// bitsets of any size can be used as map keys. Trailing empty words are ignored,
+// so equal sets always have equal keys
+func (b *BitSet) Key() string {
+	end := len(b.words)
+	for end > 0 && b.words[end-1] == 0 {
+		end--
+	}
+
+	var builder strings.Builder
+	builder.Grow(end * 8)
+	for _, w := range b.words[:end] {
+		for shift := 0; shift < wordSize; shift += 8 {
+			builder.WriteByte(byte(w >> shift))
+		}
+	}
+	return builder.String()
+}
+
+// Hash returns a 64-bit FNV-1a hash of the set. Equal sets always have equal hashes
+func (b *BitSet) Hash() uint64 {
+	const offset, prime = 14695981039346656037, 1099511628211
+
+	end := len(b.words)
+	for end > 0 && b.words[end-1] == 0 {
+		end--
+	}
+
+	hash := uint64(offset)
+	for _, w := range b.words[:end] {
+		for shift := 0; shift < wordSize; shift += 8 {
+			hash ^= (w >> shift) & 0xff
+			hash *= prime
+		}
+	}
+	return hash
+}
+
+func (b *BitSet) String() string {
+	return fmt.Sprint(b.Values())
+}
@@ -0,0 +1,74 @@
+package datastructures
+
+import (
+	"testing"
+
+	"golang.org/x/exp/slices"
+)
+
+func bitSetOf(b *BitSet, vals ...int) *BitSet {
+	for _, v := range vals {
+		b.Set(v)
+	}
+	return b
+}
+
+func expectPanic(t *testing.T, name string, fn func()) {
+	t.Helper()
+	defer func() {
+		if recover() == nil {
+			t.Errorf("%v didn't panic", name)
+		}
+	}()
+	fn()
+}
+
+func TestBitSetUnionWith(t *testing.T) {
+	growable := bitSetOf(&BitSet{}, 1, 3)
+	growable.UnionWith(bitSetOf(&BitSet{}, 3, 200))
+	if got, want := growable.Values(), []int{1, 3, 200}; !slices.Equal(got, want) {
+		t.Errorf("growable union = %v, want %v", got, want)
+	}
+
+	fixed := bitSetOf(NewFixedBitSet(10), 2)
+	fixed.UnionWith(bitSetOf(&BitSet{}, 9))
+	if got, want := fixed.Values(), []int{2, 9}; !slices.Equal(got, want) {
+		t.Errorf("fixed union = %v, want %v", got, want)
+	}
+
+	// Bits past the size, in the same word or a later one, aren't allowed in
+	for _, v := range []int{10, 20, 63, 64, 500} {
+		fixed := NewFixedBitSet(10)
+		expectPanic(t, "union with an out of range bit", func() { fixed.UnionWith(bitSetOf(&BitSet{}, v)) })
+		if fixed.Test(v) {
+			t.Errorf("fixed(10) contains %d after a union", v)
+		}
+	}
+}
+
+func TestBitSetShift(t *testing.T) {
+	tests := []struct {
+		name  string
+		b     *BitSet
+		n     int
+		left  []int
+		right []int
+	}{
+		{"growable", bitSetOf(&BitSet{}, 0, 5, 63), 2, []int{2, 7, 65}, []int{3, 61}},
+		{"negative", bitSetOf(&BitSet{}, 0, 5, 63), -2, []int{3, 61}, []int{2, 7, 65}},
+		{"fixed drops overflow", bitSetOf(NewFixedBitSet(8), 0, 6, 7), 2, []int{2}, []int{4, 5}},
+		{"fixed negative", bitSetOf(NewFixedBitSet(8), 0, 6, 7), -2, []int{4, 5}, []int{2}},
+		{"zero", bitSetOf(&BitSet{}, 1, 2), 0, []int{1, 2}, []int{1, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.b.ShiftLeft(tt.n).Values(); !slices.Equal(got, tt.left) {
+				t.Errorf("ShiftLeft(%d) = %v, want %v", tt.n, got, tt.left)
+			}
+			if got := tt.b.ShiftRight(tt.n).Values(); !slices.Equal(got, tt.right) {
+				t.Errorf("ShiftRight(%d) = %v, want %v", tt.n, got, tt.right)
+			}
+		})
+	}
+}
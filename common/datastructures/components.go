@@ -0,0 +1,101 @@
+package datastructures
+
+// Components is the result of labelling connected components. Each component
+// is identified by its index in `Members`
+type Components[T comparable] struct {
+	Members [][]T
+	label   map[T]int
+}
+
+// Len returns the number of components
+func (c *Components[T]) Len() int {
+	return len(c.Members)
+}
+
+// Sizes returns the number of cells in each component
+func (c *Components[T]) Sizes() []int {
+	sizes := make([]int, len(c.Members))
+	for i, m := range c.Members {
+		sizes[i] = len(m)
+	}
+	return sizes
+}
+
+// Label returns the index of the component containing `cell`, or false if it wasn't labelled
+func (c *Components[T]) Label(cell T) (int, bool) {
+	l, ok := c.label[cell]
+	return l, ok
+}
+
+// Of returns every cell in the same component as `cell`
+func (c *Components[T]) Of(cell T) []T {
+	if l, ok := c.label[cell]; ok {
+		return c.Members[l]
+	}
+	return nil
+}
+
+// ConnectedComponents groups `cells` into components, where each cell is connected to
+// the results of `neighbours`. Neighbours that aren't in `cells` are ignored
+func ConnectedComponents[T comparable](cells []T, neighbours func(T) []T) Components[T] {
+	uf := NewUnionFind(cells...)
+	for _, cell := range cells {
+		for _, adj := range neighbours(cell) {
+			if uf.Has(adj) {
+				uf.Union(cell, adj)
+			}
+		}
+	}
+
+	c := Components[T]{Members: uf.Sets(), label: make(map[T]int, len(cells))}
+	for i, m := range c.Members {
+		for _, cell := range m {
+			c.label[cell] = i
+		}
+	}
+	return c
+}
+
+// GridComponents labels the components of `grid`, indexed by [row][col]. Horizontally
+// or vertically adjacent cells are connected if `connected` returns true for them. Cells
+// for which `include` returns false are left out entirely
+func GridComponents[E any](grid [][]E, include func(E) bool, connected func(a, b E) bool) Components[[2]int] {
+	var cells [][2]int
+	for row := range grid {
+		for col := range grid[row] {
+			if include(grid[row][col]) {
+				cells = append(cells, [2]int{row, col})
+			}
+		}
+	}
+
+	return ConnectedComponents(cells, func(p [2]int) [][2]int {
+		var adj [][2]int
+		for _, d := range [][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+			row, col := p[0]+d[0], p[1]+d[1]
+			if row < 0 || row >= len(grid) || col < 0 || col >= len(grid[row]) {
+				continue
+			}
+			if connected(grid[p[0]][p[1]], grid[row][col]) {
+				adj = append(adj, [2]int{row, col})
+			}
+		}
+		return adj
+	})
+}
+
+// VoxelComponents labels the components of a set of voxels, given as [x, y, z], where
+// voxels sharing a face are connected
+func VoxelComponents(voxels [][3]int) Components[[3]int] {
+	return ConnectedComponents(voxels, func(v [3]int) [][3]int {
+		adj := make([][3]int, 0, 6)
+		for axis := 0; axis < 3; axis++ {
+			for _, d := range []int{-1, 1} {
+				n := v
+				n[axis] += d
+				adj = append(adj, n)
+			}
+		}
+		return adj
+	})
+}
@@ -0,0 +1,192 @@
+package datastructures
+
+import "github.com/ShajeshJ/adventofcode_2022/common/util"
+
+// RingNode is a stable handle to a single element of a `Ring`. A node stays
+// valid as the ring is rearranged, until it's removed
+type RingNode[T any] struct {
+	Value T
+	prev  *RingNode[T]
+	next  *RingNode[T]
+	ring  *Ring[T]
+}
+
+// Next returns the node after `n`, wrapping around the end of the ring
+func (n *RingNode[T]) Next() *RingNode[T] {
+	return n.next
+}
+
+// Prev returns the node before `n`, wrapping around the start of the ring
+func (n *RingNode[T]) Prev() *RingNode[T] {
+	return n.prev
+}
+
+// Ring is a circular doubly linked list
+type Ring[T any] struct {
+	head *RingNode[T]
+	len  int
+}
+
+func NewRing[T any]() *Ring[T] {
+	return &Ring[T]{}
+}
+
+// RingFromSlice creates a ring containing `vals` in order. The nodes are also
+// returned in their original order, so they can be looked up later
+func RingFromSlice[T any](vals []T) (*Ring[T], []*RingNode[T]) {
+	r := NewRing[T]()
+	nodes := make([]*RingNode[T], 0, len(vals))
+	for _, v := range vals {
+		nodes = append(nodes, r.PushBack(v))
+	}
+	return r, nodes
+}
+
+func (r *Ring[T]) Len() int {
+	return r.len
+}
+
+func (r *Ring[T]) IsEmpty() bool {
+	return r.len == 0
+}
+
+// Front returns the first node of the ring, or nil if it's empty
+func (r *Ring[T]) Front() *RingNode[T] {
+	return r.head
+}
+
+func (r *Ring[T]) checkOwner(n *RingNode[T]) {
+	if n == nil || n.ring != r {
+		panic("node does not belong to this ring")
+	}
+}
+
+// PushBack adds `val` to the end of the ring, just before `Front`
+func (r *Ring[T]) PushBack(val T) *RingNode[T] {
+	if r.head == nil {
+		n := &RingNode[T]{Value: val, ring: r}
+		n.prev, n.next = n, n
+		r.head = n
+		r.len = 1
+		return n
+	}
+	return r.InsertBefore(r.head, val)
+}
+
+// InsertAfter adds `val` directly after `mark`
+func (r *Ring[T]) InsertAfter(mark *RingNode[T], val T) *RingNode[T] {
+	r.checkOwner(mark)
+	n := &RingNode[T]{Value: val, ring: r}
+	r.link(mark, n)
+	return n
+}
+
+// InsertBefore adds `val` directly before `mark`
+func (r *Ring[T]) InsertBefore(mark *RingNode[T], val T) *RingNode[T] {
+	r.checkOwner(mark)
+	return r.InsertAfter(mark.prev, val)
+}
+
+// link places the detached node `n` after `mark`
+func (r *Ring[T]) link(mark, n *RingNode[T]) {
+	n.prev, n.next = mark, mark.next
+	mark.next.prev = n
+	mark.next = n
+	r.len++
+}
+
+// unlink detaches `n` from its neighbours, keeping `Front` valid
+func (r *Ring[T]) unlink(n *RingNode[T]) {
+	if r.len == 1 {
+		r.head = nil
+	} else if r.head == n {
+		r.head = n.next
+	}
+	n.prev.next = n.next
+	n.next.prev = n.prev
+	n.prev, n.next = nil, nil
+	r.len--
+}
+
+// Remove takes `n` out of the ring and returns its value. `n` can't be used afterwards
+func (r *Ring[T]) Remove(n *RingNode[T]) T {
+	r.checkOwner(n)
+	r.unlink(n)
+	n.ring = nil
+	return n.Value
+}
+
+// Walk returns the node `k` steps away from `n`, going backwards if `k` is
+// negative. Complete loops around the ring are skipped
+func (r *Ring[T]) Walk(n *RingNode[T], k int) *RingNode[T] {
+	r.checkOwner(n)
+	return walk(n, util.Mod(k, r.len), r.len)
+}
+
+// walk moves `k` steps forward around a loop of `size` nodes, going the other
+// way around if that's shorter. `k` must be in the range [0, size)
+func walk[T any](n *RingNode[T], k, size int) *RingNode[T] {
+	if k <= size/2 {
+		for ; k > 0; k-- {
+			n = n.next
+		}
+	} else {
+		for k = size - k; k > 0; k-- {
+			n = n.prev
+		}
+	}
+	return n
+}
+
+// Move shifts `n` by `k` places past the other elements, going backwards if
+// `k` is negative. Since `n` itself doesn't count, moving by `Len()-1` places
+// leaves the ring unchanged
+func (r *Ring[T]) Move(n *RingNode[T], k int) {
+	r.checkOwner(n)
+	if r.len <= 2 {
+		return
+	}
+
+	others := r.len - 1
+	k = util.Mod(k, others)
+	if k == 0 {
+		return
+	}
+
+	mark := n.prev
+	r.unlink(n)
+	r.link(walk(mark, k, others), n)
+}
+
+// Each calls `fn` on every value once, going forwards from `from`, until `fn` returns false
+func (r *Ring[T]) Each(from *RingNode[T], fn func(val T) bool) {
+	if r.IsEmpty() {
+		return
+	}
+	r.checkOwner(from)
+
+	n := from
+	for {
+		if !fn(n.Value) {
+			return
+		}
+		if n = n.next; n == from {
+			return
+		}
+	}
+}
+
+// ToSlice returns the values of the ring in order, starting from `from`. If
+// `from` is nil, it starts from `Front`
+func (r *Ring[T]) ToSlice(from *RingNode[T]) []T {
+	if from == nil {
+		from = r.head
+	}
+
+	output := make([]T, 0, r.len)
+	r.Each(from, func(val T) bool {
+		output = append(output, val)
+		return true
+	})
+	return output
+}
@@ -0,0 +1,127 @@
+package datastructures
+
+// Set is an unordered collection of unique values. Since it's backed by a map,
+// it can be iterated over directly with `range`
+type Set[T comparable] map[T]struct{}
+
+// NewSet creates a set containing `vals`
+func NewSet[T comparable](vals ...T) Set[T] {
+	s := make(Set[T], len(vals))
+	s.Add(vals...)
+	return s
+}
+
+func (s Set[T]) Len() int {
+	return len(s)
+}
+
+func (s Set[T]) IsEmpty() bool {
+	return len(s) == 0
+}
+
+func (s Set[T]) Add(vals ...T) {
+	for _, val := range vals {
+		s[val] = struct{}{}
+	}
+}
+
+func (s Set[T]) Remove(vals ...T) {
+	for _, val := range vals {
+		delete(s, val)
+	}
+}
+
+func (s Set[T]) Has(val T) bool {
+	_, ok := s[val]
+	return ok
+}
+
+// Values returns the contents of the set as a slice, in no particular order
+func (s Set[T]) Values() []T {
+	vals := make([]T, 0, len(s))
+	for val := range s {
+		vals = append(vals, val)
+	}
+	return vals
+}
+
+func (s Set[T]) Clone() Set[T] {
+	clone := make(Set[T], len(s))
+	for val := range s {
+		clone[val] = struct{}{}
+	}
+	return clone
+}
+
+// Equal returns true if both sets contain exactly the same values
+func (s Set[T]) Equal(other Set[T]) bool {
+	if len(s) != len(other) {
+		return false
+	}
+	for val := range s {
+		if !other.Has(val) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSubsetOf returns true if every value of `s` is also in `other`
+func (s Set[T]) IsSubsetOf(other Set[T]) bool {
+	if len(s) > len(other) {
+		return false
+	}
+	for val := range s {
+		if !other.Has(val) {
+			return false
+		}
+	}
+	return true
+}
+
+// Union returns a new set with the values that are in `s` or any of `others`
+func (s Set[T]) Union(others ...Set[T]) Set[T] {
+	union := s.Clone()
+	for _, other := range others {
+		for val := range other {
+			union[val] = struct{}{}
+		}
+	}
+	return union
+}
+
+// Intersection returns a new set with the values that are in `s` and all of `others`
+func (s Set[T]) Intersection(others ...Set[T]) Set[T] {
+	intersection := Set[T]{}
+	for val := range s {
+		inAll := true
+		for _, other := range others {
+			if !other.Has(val) {
+				inAll = false
+				break
+			}
+		}
+		if inAll {
+			intersection[val] = struct{}{}
+		}
+	}
+	return intersection
+}
+
+// Difference returns a new set with the values that are in `s` but none of `others`
+func (s Set[T]) Difference(others ...Set[T]) Set[T] {
+	difference := Set[T]{}
+	for val := range s {
+		inAny := false
+		for _, other := range others {
+			if other.Has(val) {
+				inAny = true
+				break
+			}
+		}
+		if !inAny {
+			difference[val] = struct{}{}
+		}
+	}
+	return difference
+}
@@ -1,7 +1,17 @@
 package datastructures
 
+// Stack is a LIFO collection. The top of the stack is kept at the end of
+// the underlying slice, so that pushing and popping doesn't shift any of the
+// existing elements
 type Stack[T any] []T
 
+// NewStack creates a stack containing `vals`, where the last value is the top
+func NewStack[T any](vals ...T) Stack[T] {
+	s := make(Stack[T], len(vals))
+	copy(s, vals)
+	return s
+}
+
 func (s *Stack[T]) Len() int {
 	return len(*s)
 }
@@ -11,27 +21,72 @@ func (s *Stack[T]) IsEmpty() bool {
 }
 
 func (s *Stack[T]) Push(val T) {
-	s.PushN([]T{val})
+	*s = append(*s, val)
 }
 
 func (s *Stack[T]) Pop() (T, bool) {
-	val, ok := s.PopN(1)
-	return val[0], ok
+	if s.IsEmpty() {
+		return *new(T), false
+	}
+
+	top := len(*s) - 1
+	val := (*s)[top]
+	(*s)[top] = *new(T) // Release the reference held by the backing array
+	*s = (*s)[:top]
+	return val, true
 }
 
-func (s *Stack[T]) PopN(n int) ([]T, bool) {
-	if s.Len() < n {
-		return *new([]T), false
+// Peek returns the top of the stack without removing it
+func (s *Stack[T]) Peek() (T, bool) {
+	if s.IsEmpty() {
+		return *new(T), false
+	}
+	return (*s)[len(*s)-1], true
+}
+
+// PeekN returns the top `n` values without removing them. The values are ordered
+// from deepest to top, which is the same order `PopN` would return them in
+func (s *Stack[T]) PeekN(n int) ([]T, bool) {
+	if n < 0 || s.Len() < n {
+		return nil, false
 	}
 
-	// Explicitly create a new slice to avoid memory overwriting during push
 	val := make([]T, n)
-	copy(val, (*s)[:n])
+	copy(val, (*s)[len(*s)-n:])
+	return val, true
+}
+
+// PopN removes the top `n` values as a block. The values are ordered from
+// deepest to top, so a subsequent `PushN` keeps their relative order intact
+func (s *Stack[T]) PopN(n int) ([]T, bool) {
+	// Explicitly create a new slice to avoid memory overwriting during push
+	val, ok := s.PeekN(n)
+	if !ok {
+		return nil, false
+	}
 
-	*s = (*s)[n:]
+	var zero T
+	for i := len(*s) - n; i < len(*s); i++ {
+		(*s)[i] = zero
+	}
+	*s = (*s)[:len(*s)-n]
 	return val, true
 }
 
+// PushN pushes `val` as a block, such that the last value becomes the new top
 func (s *Stack[T]) PushN(val []T) {
-	*s = append(val, *s...)
+	*s = append(*s, val...)
+}
+
+// Iterator returns a function that yields each value from the top of the stack
+// to the bottom. The returned bool is false once the stack is exhausted
+func (s *Stack[T]) Iterator() func() (T, bool) {
+	i := len(*s)
+	return func() (T, bool) {
+		if i <= 0 || i > len(*s) {
+			return *new(T), false
+		}
+		i--
+		return (*s)[i], true
+	}
 }
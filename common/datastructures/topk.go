@@ -0,0 +1,105 @@
+package datastructures
+
+import (
+	"sort"
+
+	"golang.org/x/exp/constraints"
+)
+
+// TopK keeps track of the K best values pushed into it. Internally it's backed by
+// a bounded min-heap (with respect to `less`), so the worst kept value is always at
+// the root and can be evicted in O(log K) time
+type TopK[T any] struct {
+	k    int
+	less func(a, b T) bool
+	heap []T
+}
+
+// NewTopK creates a TopK that keeps the `k` largest values, where `less(a, b)`
+// reports whether `a` ranks below `b`
+func NewTopK[T any](k int, less func(a, b T) bool) *TopK[T] {
+	if k < 1 {
+		k = 1
+	}
+	return &TopK[T]{k: k, less: less, heap: make([]T, 0, k)}
+}
+
+// NewBottomK creates a TopK that keeps the `k` smallest values, where `less(a, b)`
+// reports whether `a` ranks below `b`
+func NewBottomK[T any](k int, less func(a, b T) bool) *TopK[T] {
+	return NewTopK(k, func(a, b T) bool { return less(b, a) })
+}
+
+// NewTopKOrdered creates a TopK that keeps the `k` largest values using the natural ordering of `T`
+func NewTopKOrdered[T constraints.Ordered](k int) *TopK[T] {
+	return NewTopK(k, func(a, b T) bool { return a < b })
+}
+
+func (t *TopK[T]) Len() int {
+	return len(t.heap)
+}
+
+func (t *TopK[T]) Cap() int {
+	return t.k
+}
+
+// Push adds `val` if there are fewer than K values, or if it ranks above the worst
+// value currently kept (which gets evicted). Returns whether `val` was kept
+func (t *TopK[T]) Push(val T) bool {
+	if len(t.heap) < t.k {
+		t.heap = append(t.heap, val)
+		t.siftUp(len(t.heap) - 1)
+		return true
+	}
+
+	if !t.less(t.heap[0], val) {
+		return false
+	}
+
+	t.heap[0] = val
+	t.siftDown(0)
+	return true
+}
+
+// Worst returns the lowest ranked value that is still being kept
+func (t *TopK[T]) Worst() (T, bool) {
+	if len(t.heap) == 0 {
+		return *new(T), false
+	}
+	return t.heap[0], true
+}
+
+// Sorted returns a copy of the kept values, ordered from best to worst
+func (t *TopK[T]) Sorted() []T {
+	vals := make([]T, len(t.heap))
+	copy(vals, t.heap)
+	sort.SliceStable(vals, func(i, j int) bool { return t.less(vals[j], vals[i]) })
+	return vals
+}
+
+func (t *TopK[T]) siftUp(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !t.less(t.heap[i], t.heap[parent]) {
+			return
+		}
+		t.heap[i], t.heap[parent] = t.heap[parent], t.heap[i]
+		i = parent
+	}
+}
+
+func (t *TopK[T]) siftDown(i int) {
+	for {
+		smallest := i
+		for _, child := range [2]int{2*i + 1, 2*i + 2} {
+			if child < len(t.heap) && t.less(t.heap[child], t.heap[smallest]) {
+				smallest = child
+			}
+		}
+		if smallest == i {
+			return
+		}
+		t.heap[i], t.heap[smallest] = t.heap[smallest], t.heap[i]
+		i = smallest
+	}
+}
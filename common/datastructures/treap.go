@@ -0,0 +1,225 @@
+package datastructures
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// TreapNode is a stable handle to a single element of a `Treap`. A node stays
+// valid as the treap is rearranged, until it's removed
+type TreapNode[T any] struct {
+	Value    T
+	priority uint64
+	size     int // Number of nodes in the subtree rooted here
+	left     *TreapNode[T]
+	right    *TreapNode[T]
+	parent   *TreapNode[T]
+	tree     *Treap[T]
+}
+
+func sizeOf[T any](n *TreapNode[T]) int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+// update recalculates the size of `n` and points its children back at it
+func (n *TreapNode[T]) update() {
+	n.size = 1 + sizeOf(n.left) + sizeOf(n.right)
+	if n.left != nil {
+		n.left.parent = n
+	}
+	if n.right != nil {
+		n.right.parent = n
+	}
+}
+
+// Treap is an implicit treap: a balanced binary tree ordered by position
+// rather than by value. It works like a slice where finding, inserting and
+// removing at any index, and finding the index of a node, are all O(log n)
+type Treap[T any] struct {
+	root *TreapNode[T]
+	rng  *rand.Rand
+}
+
+func NewTreap[T any]() *Treap[T] {
+	// A fixed seed keeps the tree shape, and so the running time, reproducible
+	return &Treap[T]{rng: rand.New(rand.NewSource(1))}
+}
+
+// TreapFromSlice creates a treap containing `vals` in order. The nodes are also
+// returned in their original order, so they can be looked up later
+func TreapFromSlice[T any](vals []T) (*Treap[T], []*TreapNode[T]) {
+	t := NewTreap[T]()
+	nodes := make([]*TreapNode[T], 0, len(vals))
+	for _, v := range vals {
+		nodes = append(nodes, t.InsertAt(t.Len(), v))
+	}
+	return t, nodes
+}
+
+func (t *Treap[T]) Len() int {
+	return sizeOf(t.root)
+}
+
+func (t *Treap[T]) IsEmpty() bool {
+	return t.root == nil
+}
+
+func (t *Treap[T]) checkOwner(n *TreapNode[T]) {
+	if n == nil || n.tree != t {
+		panic("node does not belong to this treap")
+	}
+}
+
+func (t *Treap[T]) checkIndex(i, max int) {
+	if i < 0 || i > max {
+		panic(fmt.Sprintf("index %d out of range [0, %d]", i, max))
+	}
+}
+
+// split separates `n` into a tree of its first `k` nodes, and a tree of the rest
+func split[T any](n *TreapNode[T], k int) (*TreapNode[T], *TreapNode[T]) {
+	if n == nil {
+		return nil, nil
+	}
+
+	if sizeOf(n.left) < k {
+		l, r := split(n.right, k-sizeOf(n.left)-1)
+		n.right = l
+		n.update()
+		return n, r
+	}
+
+	l, r := split(n.left, k)
+	n.left = r
+	n.update()
+	return l, n
+}
+
+// merge joins two trees, where all of `a` comes before all of `b`
+func merge[T any](a, b *TreapNode[T]) *TreapNode[T] {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+
+	if a.priority > b.priority {
+		a.right = merge(a.right, b)
+		a.update()
+		return a
+	}
+	b.left = merge(a, b.left)
+	b.update()
+	return b
+}
+
+func (t *Treap[T]) setRoot(n *TreapNode[T]) {
+	t.root = n
+	if n != nil {
+		n.parent = nil
+	}
+}
+
+// At returns the node at index `i`
+func (t *Treap[T]) At(i int) *TreapNode[T] {
+	t.checkIndex(i, t.Len()-1)
+
+	n := t.root
+	for {
+		switch left := sizeOf(n.left); {
+		case i < left:
+			n = n.left
+		case i == left:
+			return n
+		default:
+			i -= left + 1
+			n = n.right
+		}
+	}
+}
+
+// IndexOf returns the current index of `n`
+func (t *Treap[T]) IndexOf(n *TreapNode[T]) int {
+	t.checkOwner(n)
+
+	idx := sizeOf(n.left)
+	for ; n.parent != nil; n = n.parent {
+		if n.parent.right == n {
+			idx += sizeOf(n.parent.left) + 1
+		}
+	}
+	return idx
+}
+
+// insertNode places the detached node `n` at index `i`
+func (t *Treap[T]) insertNode(i int, n *TreapNode[T]) {
+	n.left, n.right, n.parent = nil, nil, nil
+	n.update()
+
+	l, r := split(t.root, i)
+	t.setRoot(merge(merge(l, n), r))
+}
+
+// removeNode detaches `n` from the tree
+func (t *Treap[T]) removeNode(n *TreapNode[T]) {
+	i := t.IndexOf(n)
+	l, r := split(t.root, i)
+	_, r = split(r, 1)
+	t.setRoot(merge(l, r))
+	n.left, n.right, n.parent = nil, nil, nil
+}
+
+// InsertAt adds `val` at index `i`, shifting everything from `i` onwards up by one.
+// `i` may be `Len()`, to add it to the end
+func (t *Treap[T]) InsertAt(i int, val T) *TreapNode[T] {
+	t.checkIndex(i, t.Len())
+
+	n := &TreapNode[T]{Value: val, priority: t.rng.Uint64(), tree: t}
+	t.insertNode(i, n)
+	return n
+}
+
+// RemoveAt takes out the value at index `i`, shifting everything after it down by one
+func (t *Treap[T]) RemoveAt(i int) T {
+	return t.Remove(t.At(i))
+}
+
+// Remove takes `n` out of the treap and returns its value. `n` can't be used afterwards
+func (t *Treap[T]) Remove(n *TreapNode[T]) T {
+	t.checkOwner(n)
+	t.removeNode(n)
+	n.tree = nil
+	return n.Value
+}
+
+// MoveTo shifts `n` so that it ends up at index `i`, keeping the handle valid
+func (t *Treap[T]) MoveTo(n *TreapNode[T], i int) {
+	t.checkOwner(n)
+	t.checkIndex(i, t.Len()-1)
+
+	t.removeNode(n)
+	t.insertNode(i, n)
+}
+
+// ToSlice returns all of the values in order
+func (t *Treap[T]) ToSlice() []T {
+	output := make([]T, 0, t.Len())
+
+	// In-order traversal, without recursing
+	var stack Stack[*TreapNode[T]]
+	for n := t.root; n != nil || !stack.IsEmpty(); {
+		if n != nil {
+			stack.Push(n)
+			n = n.left
+			continue
+		}
+		n, _ = stack.Pop()
+		output = append(output, n.Value)
+		n = n.right
+	}
+	return output
+}
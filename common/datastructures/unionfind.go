@@ -0,0 +1,125 @@
+package datastructures
+
+// UnionFind tracks a collection of values partitioned into disjoint sets, with
+// near constant time merging and lookups thanks to path compression and union
+// by rank. Values are added implicitly the first time they're used
+type UnionFind[T comparable] struct {
+	index  map[T]int
+	values []T
+	parent []int
+	rank   []int
+	size   []int
+	sets   int
+}
+
+// NewUnionFind creates a union-find where each of `vals` starts in its own set
+func NewUnionFind[T comparable](vals ...T) *UnionFind[T] {
+	uf := &UnionFind[T]{index: make(map[T]int, len(vals))}
+	for _, v := range vals {
+		uf.Add(v)
+	}
+	return uf
+}
+
+// Len returns the number of values being tracked
+func (uf *UnionFind[T]) Len() int {
+	return len(uf.values)
+}
+
+// NumSets returns the number of disjoint sets
+func (uf *UnionFind[T]) NumSets() int {
+	return uf.sets
+}
+
+// Has returns whether `val` has been added
+func (uf *UnionFind[T]) Has(val T) bool {
+	_, ok := uf.index[val]
+	return ok
+}
+
+// Add puts `val` in a new set of its own, if it's not already tracked
+func (uf *UnionFind[T]) Add(val T) {
+	uf.id(val)
+}
+
+// id returns the internal index of `val`, adding it if needed
+func (uf *UnionFind[T]) id(val T) int {
+	if i, ok := uf.index[val]; ok {
+		return i
+	}
+
+	i := len(uf.values)
+	uf.index[val] = i
+	uf.values = append(uf.values, val)
+	uf.parent = append(uf.parent, i)
+	uf.rank = append(uf.rank, 0)
+	uf.size = append(uf.size, 1)
+	uf.sets++
+	return i
+}
+
+func (uf *UnionFind[T]) root(i int) int {
+	root := i
+	for uf.parent[root] != root {
+		root = uf.parent[root]
+	}
+
+	// Path compression; point everything on the way straight at the root
+	for uf.parent[i] != root {
+		uf.parent[i], i = root, uf.parent[i]
+	}
+	return root
+}
+
+// Find returns the representative value of the set containing `val`
+func (uf *UnionFind[T]) Find(val T) T {
+	return uf.values[uf.root(uf.id(val))]
+}
+
+// Union merges the sets containing `a` and `b`. Returns false if they were already the same set
+func (uf *UnionFind[T]) Union(a, b T) bool {
+	ra, rb := uf.root(uf.id(a)), uf.root(uf.id(b))
+	if ra == rb {
+		return false
+	}
+
+	// Hang the shallower tree under the deeper one
+	if uf.rank[ra] < uf.rank[rb] {
+		ra, rb = rb, ra
+	}
+	uf.parent[rb] = ra
+	uf.size[ra] += uf.size[rb]
+	if uf.rank[ra] == uf.rank[rb] {
+		uf.rank[ra]++
+	}
+	uf.sets--
+	return true
+}
+
+// Connected returns whether `a` and `b` are in the same set
+func (uf *UnionFind[T]) Connected(a, b T) bool {
+	return uf.root(uf.id(a)) == uf.root(uf.id(b))
+}
+
+// SizeOf returns the number of values in the set containing `val`
+func (uf *UnionFind[T]) SizeOf(val T) int {
+	return uf.size[uf.root(uf.id(val))]
+}
+
+// Sets returns the members of every set. Sets are ordered by when their first
+// member was added, and members keep the order they were added in
+func (uf *UnionFind[T]) Sets() [][]T {
+	order := map[int]int{}
+	var sets [][]T
+	for i, v := range uf.values {
+		root := uf.root(i)
+		idx, ok := order[root]
+		if !ok {
+			idx = len(sets)
+			order[root] = idx
+			sets = append(sets, make([]T, 0, uf.size[root]))
+		}
+		sets[idx] = append(sets[idx], v)
+	}
+	return sets
+}
@@ -0,0 +1,60 @@
+package interval
+
+import (
+	"fmt"
+
+	"github.com/ShajeshJ/adventofcode_2022/common/util"
+)
+
+// Interval is a closed range of integers, i.e. both `Min` and `Max` are included
+type Interval struct {
+	Min int
+	Max int
+}
+
+// New creates an interval spanning `a` to `b`, regardless of which one is larger
+func New(a, b int) Interval {
+	if a > b {
+		a, b = b, a
+	}
+	return Interval{a, b}
+}
+
+func (i Interval) String() string {
+	return fmt.Sprintf("[%d, %d]", i.Min, i.Max)
+}
+
+// Len returns the number of integers in the interval
+func (i Interval) Len() int {
+	return i.Max - i.Min + 1
+}
+
+// Contains returns true if `x` is inside the interval
+func (i Interval) Contains(x int) bool {
+	return i.Min <= x && x <= i.Max
+}
+
+// ContainsInterval returns true if `other` is fully inside the interval
+func (i Interval) ContainsInterval(other Interval) bool {
+	return i.Min <= other.Min && other.Max <= i.Max
+}
+
+// Overlaps returns true if the intervals share at least one integer
+func (i Interval) Overlaps(other Interval) bool {
+	return i.Min <= other.Max && other.Min <= i.Max
+}
+
+// Touches returns true if the intervals overlap or are directly next to each other,
+// meaning they could be merged into a single interval
+func (i Interval) Touches(other Interval) bool {
+	return i.Min <= other.Max+1 && other.Min <= i.Max+1
+}
+
+// Intersect returns the integers shared by both intervals. If they don't overlap,
+// false is returned instead
+func (i Interval) Intersect(other Interval) (Interval, bool) {
+	if !i.Overlaps(other) {
+		return Interval{}, false
+	}
+	return Interval{util.Max(i.Min, other.Min), util.Min(i.Max, other.Max)}, true
+}
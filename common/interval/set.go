@@ -0,0 +1,150 @@
+package interval
+
+import (
+	"sort"
+
+	"github.com/ShajeshJ/adventofcode_2022/common/util"
+)
+
+// IntervalSet is a collection of integers, stored as a sorted list of disjoint intervals.
+// Intervals that overlap or touch are merged on insertion, so there's always a single
+// canonical representation of the set
+type IntervalSet struct {
+	ranges []Interval
+}
+
+// NewSet creates an interval set containing all of `intervals`
+func NewSet(intervals ...Interval) *IntervalSet {
+	s := &IntervalSet{}
+	for _, i := range intervals {
+		s.Insert(i)
+	}
+	return s
+}
+
+// Intervals returns a copy of the disjoint intervals in the set, in ascending order
+func (s *IntervalSet) Intervals() []Interval {
+	out := make([]Interval, len(s.ranges))
+	copy(out, s.ranges)
+	return out
+}
+
+// Len returns the number of disjoint intervals in the set
+func (s *IntervalSet) Len() int {
+	return len(s.ranges)
+}
+
+func (s *IntervalSet) IsEmpty() bool {
+	return len(s.ranges) == 0
+}
+
+// TotalLen returns the number of integers covered by the set
+func (s *IntervalSet) TotalLen() int {
+	total := 0
+	for _, r := range s.ranges {
+		total += r.Len()
+	}
+	return total
+}
+
+// Clear removes all intervals, but keeps the allocated memory for reuse
+func (s *IntervalSet) Clear() {
+	s.ranges = s.ranges[:0]
+}
+
+// firstTouching returns the index of the first range that ends at or after `x`-1,
+// i.e. the first range that could touch or come after an interval starting at `x`
+func (s *IntervalSet) firstTouching(x int) int {
+	return sort.Search(len(s.ranges), func(i int) bool { return s.ranges[i].Max+1 >= x })
+}
+
+// Insert adds all integers of `i` into the set, merging with any existing intervals
+func (s *IntervalSet) Insert(i Interval) {
+	start := s.firstTouching(i.Min)
+	end := start
+	for end < len(s.ranges) && s.ranges[end].Touches(i) {
+		i.Min = util.Min(i.Min, s.ranges[end].Min)
+		i.Max = util.Max(i.Max, s.ranges[end].Max)
+		end++
+	}
+
+	if start == end {
+		// Nothing to merge with, so make room for the new interval
+		s.ranges = append(s.ranges, Interval{})
+		copy(s.ranges[start+1:], s.ranges[start:])
+		s.ranges[start] = i
+		return
+	}
+
+	s.ranges[start] = i
+	s.ranges = append(s.ranges[:start+1], s.ranges[end:]...)
+}
+
+// Remove takes all integers of `i` out of the set, splitting intervals where necessary
+func (s *IntervalSet) Remove(i Interval) {
+	var kept []Interval
+	for _, r := range s.ranges {
+		if !r.Overlaps(i) {
+			kept = append(kept, r)
+			continue
+		}
+		if r.Min < i.Min {
+			kept = append(kept, Interval{r.Min, i.Min - 1})
+		}
+		if r.Max > i.Max {
+			kept = append(kept, Interval{i.Max + 1, r.Max})
+		}
+	}
+	s.ranges = kept
+}
+
+// Clip returns a new set with only the integers that are also inside `bounds`
+func (s *IntervalSet) Clip(bounds Interval) *IntervalSet {
+	clipped := &IntervalSet{}
+	for _, r := range s.ranges {
+		if i, ok := r.Intersect(bounds); ok {
+			clipped.ranges = append(clipped.ranges, i)
+		}
+	}
+	return clipped
+}
+
+// Gaps returns the intervals inside `bounds` that are not covered by the set
+func (s *IntervalSet) Gaps(bounds Interval) []Interval {
+	var gaps []Interval
+	next := bounds.Min
+	for _, r := range s.ranges {
+		if r.Max < next {
+			continue
+		}
+		if r.Min > bounds.Max {
+			break
+		}
+		if r.Min > next {
+			gaps = append(gaps, Interval{next, r.Min - 1})
+		}
+		next = r.Max + 1
+	}
+	if next <= bounds.Max {
+		gaps = append(gaps, Interval{next, bounds.Max})
+	}
+	return gaps
+}
+
+// Contains returns true if `x` is covered by the set
+func (s *IntervalSet) Contains(x int) bool {
+	idx := sort.Search(len(s.ranges), func(i int) bool { return s.ranges[i].Max >= x })
+	return idx < len(s.ranges) && s.ranges[idx].Contains(x)
+}
+
+// ContainsInterval returns true if every integer of `i` is covered by the set
+func (s *IntervalSet) ContainsInterval(i Interval) bool {
+	idx := sort.Search(len(s.ranges), func(j int) bool { return s.ranges[j].Max >= i.Min })
+	return idx < len(s.ranges) && s.ranges[idx].ContainsInterval(i)
+}
+
+// Overlaps returns true if at least one integer of `i` is covered by the set
+func (s *IntervalSet) Overlaps(i Interval) bool {
+	idx := sort.Search(len(s.ranges), func(j int) bool { return s.ranges[j].Max >= i.Min })
+	return idx < len(s.ranges) && s.ranges[idx].Overlaps(i)
+}
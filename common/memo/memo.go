@@ -0,0 +1,170 @@
+package memo
+
+import (
+	"container/list"
+	"sync"
+)
+
+// Stats counts how effective a cache has been
+type Stats struct {
+	Hits      int
+	Misses    int
+	Evictions int
+}
+
+// Store is anything that can hold memoized results
+type Store[K comparable, V any] interface {
+	Get(key K) (V, bool)
+	Put(key K, val V)
+	Len() int
+	Stats() Stats
+}
+
+type entry[K comparable, V any] struct {
+	key K
+	val V
+}
+
+// Cache is a memoization store that can optionally be bounded in size, in which case
+// the least recently used entry is evicted to make room. It is not safe for concurrent use
+type Cache[K comparable, V any] struct {
+	maxSize int
+	stats   Stats
+
+	// Unbounded caches don't need to track usage, so they skip the overhead of the LRU list
+	values map[K]V
+	items  map[K]*list.Element
+	order  *list.List // Most recently used at the front
+}
+
+// NewCache creates a cache holding at most `maxSize` entries. A non-positive
+// `maxSize` means the cache is unbounded
+func NewCache[K comparable, V any](maxSize int) *Cache[K, V] {
+	if maxSize <= 0 {
+		return &Cache[K, V]{values: map[K]V{}}
+	}
+	return &Cache[K, V]{maxSize: maxSize, items: map[K]*list.Element{}, order: list.New()}
+}
+
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	var val V
+	var ok bool
+	if c.maxSize <= 0 {
+		val, ok = c.values[key]
+	} else if el, found := c.items[key]; found {
+		c.order.MoveToFront(el)
+		val, ok = el.Value.(*entry[K, V]).val, true
+	}
+
+	if ok {
+		c.stats.Hits++
+	} else {
+		c.stats.Misses++
+	}
+	return val, ok
+}
+
+func (c *Cache[K, V]) Put(key K, val V) {
+	if c.maxSize <= 0 {
+		c.values[key] = val
+		return
+	}
+
+	if el, ok := c.items[key]; ok {
+		el.Value.(*entry[K, V]).val = val
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&entry[K, V]{key, val})
+	if c.order.Len() > c.maxSize {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*entry[K, V]).key)
+		c.stats.Evictions++
+	}
+}
+
+func (c *Cache[K, V]) Len() int {
+	if c.maxSize <= 0 {
+		return len(c.values)
+	}
+	return len(c.items)
+}
+
+func (c *Cache[K, V]) Stats() Stats {
+	return c.stats
+}
+
+// SyncCache is a Cache that is safe for concurrent use
+type SyncCache[K comparable, V any] struct {
+	mu    sync.Mutex
+	cache *Cache[K, V]
+}
+
+// NewSyncCache creates a concurrency-safe cache holding at most `maxSize` entries.
+// A non-positive `maxSize` means the cache is unbounded
+func NewSyncCache[K comparable, V any](maxSize int) *SyncCache[K, V] {
+	return &SyncCache[K, V]{cache: NewCache[K, V](maxSize)}
+}
+
+func (c *SyncCache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cache.Get(key)
+}
+
+func (c *SyncCache[K, V]) Put(key K, val V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cache.Put(key, val)
+}
+
+func (c *SyncCache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cache.Len()
+}
+
+func (c *SyncCache[K, V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cache.Stats()
+}
+
+// Func wraps `fn` so that results are looked up in `store` before being computed
+func Func[K comparable, V any](fn func(K) V, store Store[K, V]) func(K) V {
+	return FuncBy(fn, func(key K) K { return key }, store)
+}
+
+// FuncBy is like Func, but for functions whose argument isn't comparable. `key`
+// converts the argument into the key that results are stored under
+func FuncBy[A any, K comparable, V any](fn func(A) V, key func(A) K, store Store[K, V]) func(A) V {
+	return func(arg A) V {
+		k := key(arg)
+		if val, ok := store.Get(k); ok {
+			return val
+		}
+		val := fn(arg)
+		store.Put(k, val)
+		return val
+	}
+}
+
+// Recursive is like Func, but for recursive functions. `fn` is given the memoized
+// version of itself as `self`, which it should use for any recursive calls
+func Recursive[K comparable, V any](fn func(self func(K) V, key K) V, store Store[K, V]) func(K) V {
+	return RecursiveBy(fn, func(key K) K { return key }, store)
+}
+
+// RecursiveBy is like Recursive, but for functions whose argument isn't comparable.
+// `key` converts the argument into the key that results are stored under
+func RecursiveBy[A any, K comparable, V any](
+	fn func(self func(A) V, arg A) V,
+	key func(A) K,
+	store Store[K, V],
+) func(A) V {
+	var memoized func(A) V
+	memoized = FuncBy(func(arg A) V { return fn(memoized, arg) }, key, store)
+	return memoized
+}
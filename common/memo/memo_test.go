@@ -0,0 +1,157 @@
+package memo
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewCache[string, int](2)
+	c.Put("a", 1)
+	c.Put("b", 2)
+
+	// Reading "a" makes "b" the least recently used, so it goes first
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal(`Get("a") missed before anything was evicted`)
+	}
+	c.Put("c", 3)
+	if _, ok := c.Get("b"); ok {
+		t.Error(`"b" should have been evicted`)
+	}
+	if got, ok := c.Get("a"); !ok || got != 1 {
+		t.Errorf(`Get("a") = %v, %v, want 1, true`, got, ok)
+	}
+
+	// Updating "c" also counts as using it, leaving "a" to be evicted next
+	c.Put("c", 30)
+	c.Put("d", 4)
+	if _, ok := c.Get("a"); ok {
+		t.Error(`"a" should have been evicted`)
+	}
+	if got, ok := c.Get("c"); !ok || got != 30 {
+		t.Errorf(`Get("c") = %v, %v, want 30, true`, got, ok)
+	}
+
+	if c.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", c.Len())
+	}
+	if got := c.Stats().Evictions; got != 2 {
+		t.Errorf("Evictions = %d, want 2", got)
+	}
+}
+
+func TestCacheStats(t *testing.T) {
+	for _, maxSize := range []int{0, 10} {
+		c := NewCache[int, int](maxSize)
+		c.Get(1)
+		c.Put(1, 10)
+		c.Get(1)
+		c.Get(1)
+		c.Get(2)
+
+		want := Stats{Hits: 2, Misses: 2}
+		if got := c.Stats(); got != want {
+			t.Errorf("NewCache(%d): Stats() = %+v, want %+v", maxSize, got, want)
+		}
+		if c.Len() != 1 {
+			t.Errorf("NewCache(%d): Len() = %d, want 1", maxSize, c.Len())
+		}
+	}
+}
+
+func TestSyncCache(t *testing.T) {
+	c := NewSyncCache[int, int](0)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				if _, ok := c.Get(i); !ok {
+					c.Put(i, i*i)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if c.Len() != 100 {
+		t.Errorf("Len() = %d, want 100", c.Len())
+	}
+	if got, ok := c.Get(7); !ok || got != 49 {
+		t.Errorf("Get(7) = %v, %v, want 49, true", got, ok)
+	}
+	if stats := c.Stats(); stats.Hits+stats.Misses != 8*100+1 {
+		t.Errorf("Stats() = %+v, want %d lookups in total", stats, 8*100+1)
+	}
+}
+
+func TestFunc(t *testing.T) {
+	calls := 0
+	double := Func(func(x int) int {
+		calls++
+		return 2 * x
+	}, Store[int, int](NewCache[int, int](0)))
+
+	for _, x := range []int{1, 2, 1, 1, 2} {
+		if got := double(x); got != 2*x {
+			t.Errorf("double(%d) = %d, want %d", x, got, 2*x)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("wrapped function called %d times, want 2", calls)
+	}
+}
+
+func TestRecursive(t *testing.T) {
+	calls := 0
+	cache := NewCache[int, int](0)
+	fib := Recursive(func(self func(int) int, n int) int {
+		calls++
+		if n < 2 {
+			return n
+		}
+		return self(n-1) + self(n-2)
+	}, Store[int, int](cache))
+
+	if got := fib(90); got != 2880067194370816120 {
+		t.Errorf("fib(90) = %d, want 2880067194370816120", got)
+	}
+	// Each of fib(0) to fib(90) is only worked out once
+	if calls != 91 {
+		t.Errorf("fib(90) made %d calls, want 91", calls)
+	}
+	// fib(n) hits the cache for fib(n-2) from n = 3, as fib(0) isn't needed until n = 2
+	if stats := cache.Stats(); stats.Misses != 91 || stats.Hits != 88 {
+		t.Errorf("Stats() = %+v, want 91 misses and 88 hits", stats)
+	}
+
+	// Calling it again is answered straight from the cache
+	fib(90)
+	if calls != 91 {
+		t.Errorf("second fib(90) made %d more calls, want 0", calls-91)
+	}
+}
+
+func TestRecursiveBy(t *testing.T) {
+	// Slices aren't comparable, so the key is the slice's length
+	calls := 0
+	sumSuffixes := RecursiveBy(func(self func([]int) int, x []int) int {
+		calls++
+		if len(x) == 0 {
+			return 0
+		}
+		return x[0] + self(x[1:])
+	}, func(x []int) int { return len(x) }, Store[int, int](NewCache[int, int](0)))
+
+	if got := sumSuffixes([]int{1, 2, 3, 4}); got != 10 {
+		t.Errorf("sumSuffixes = %d, want 10", got)
+	}
+	if got := sumSuffixes([]int{9, 9, 9}); got != 9 {
+		t.Errorf("sumSuffixes of a cached length = %d, want the cached 9", got)
+	}
+	if calls != 5 {
+		t.Errorf("made %d calls, want 5", calls)
+	}
+}
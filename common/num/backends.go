@@ -0,0 +1,167 @@
+package num
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/ShajeshJ/adventofcode_2022/common/util"
+)
+
+// Int is the machine integer backend. It's the fastest, but returns `util.ErrOverflow`
+// on results that don't fit in an int
+type Int struct{}
+
+func (Int) Name() string                { return "int" }
+func (Int) FromInt(x int) int           { return x }
+func (Int) Parse(s string) (int, error) { return strconv.Atoi(s) }
+func (Int) Add(a, b int) (int, error)   { return util.CheckedAdd(a, b) }
+func (Int) Mul(a, b int) (int, error)   { return util.CheckedMul(a, b) }
+func (Int) String(x int) string         { return strconv.Itoa(x) }
+
+func (Int) Sub(a, b int) (int, error) {
+	if b == -b && b != 0 {
+		// Negating the smallest int overflows
+		return 0, fmt.Errorf("%v - %v: %w", a, b, util.ErrOverflow)
+	}
+	return util.CheckedAdd(a, -b)
+}
+
+func (Int) Div(a, b int) (int, error) {
+	if b == 0 {
+		return 0, ErrDivByZero
+	}
+	if a%b != 0 {
+		return 0, fmt.Errorf("%v / %v: %w", a, b, ErrInexact)
+	}
+	return a / b, nil
+}
+
+func (Int) FloorDiv(a, b int) (int, error) {
+	if b == 0 {
+		return 0, ErrDivByZero
+	}
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q, nil
+}
+
+func (Int) Mod(a, b int) (int, error) {
+	if b == 0 {
+		return 0, ErrDivByZero
+	}
+	return util.Mod(a, b), nil
+}
+
+func (Int) Cmp(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Big is the arbitrary-precision integer backend. Values are never modified
+// once created, so they can be shared freely
+type Big struct{}
+
+func (Big) Name() string                        { return "big" }
+func (Big) FromInt(x int) *big.Int              { return big.NewInt(int64(x)) }
+func (Big) Cmp(a, b *big.Int) int               { return a.Cmp(b) }
+func (Big) String(x *big.Int) string            { return x.String() }
+func (Big) Add(a, b *big.Int) (*big.Int, error) { return new(big.Int).Add(a, b), nil }
+func (Big) Sub(a, b *big.Int) (*big.Int, error) { return new(big.Int).Sub(a, b), nil }
+func (Big) Mul(a, b *big.Int) (*big.Int, error) { return new(big.Int).Mul(a, b), nil }
+
+func (Big) Parse(s string) (*big.Int, error) {
+	x, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid integer %q", s)
+	}
+	return x, nil
+}
+
+func (Big) Div(a, b *big.Int) (*big.Int, error) {
+	if b.Sign() == 0 {
+		return nil, ErrDivByZero
+	}
+	q, r := new(big.Int).QuoRem(a, b, new(big.Int))
+	if r.Sign() != 0 {
+		return nil, fmt.Errorf("%v / %v: %w", a, b, ErrInexact)
+	}
+	return q, nil
+}
+
+func (Big) FloorDiv(a, b *big.Int) (*big.Int, error) {
+	if b.Sign() == 0 {
+		return nil, ErrDivByZero
+	}
+	q, r := new(big.Int).QuoRem(a, b, new(big.Int))
+	if r.Sign() != 0 && (r.Sign() < 0) != (b.Sign() < 0) {
+		q.Sub(q, big.NewInt(1))
+	}
+	return q, nil
+}
+
+func (Big) Mod(a, b *big.Int) (*big.Int, error) {
+	if b.Sign() == 0 {
+		return nil, ErrDivByZero
+	}
+	// big.Int's Mod is Euclidean, so it's already non-negative
+	return new(big.Int).Mod(a, b), nil
+}
+
+// Rat is the exact rational backend. Division never loses precision, so it's
+// suited to solving equations where intermediate results may be fractions
+type Rat struct{}
+
+func (Rat) Name() string                        { return "rat" }
+func (Rat) FromInt(x int) *big.Rat              { return big.NewRat(int64(x), 1) }
+func (Rat) Cmp(a, b *big.Rat) int               { return a.Cmp(b) }
+func (Rat) String(x *big.Rat) string            { return x.RatString() }
+func (Rat) Add(a, b *big.Rat) (*big.Rat, error) { return new(big.Rat).Add(a, b), nil }
+func (Rat) Sub(a, b *big.Rat) (*big.Rat, error) { return new(big.Rat).Sub(a, b), nil }
+func (Rat) Mul(a, b *big.Rat) (*big.Rat, error) { return new(big.Rat).Mul(a, b), nil }
+
+func (Rat) Parse(s string) (*big.Rat, error) {
+	x, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("invalid number %q", s)
+	}
+	return x, nil
+}
+
+func (Rat) Div(a, b *big.Rat) (*big.Rat, error) {
+	if b.Sign() == 0 {
+		return nil, ErrDivByZero
+	}
+	return new(big.Rat).Quo(a, b), nil
+}
+
+func (r Rat) FloorDiv(a, b *big.Rat) (*big.Rat, error) {
+	q, err := r.Div(a, b)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Rat).SetInt(floor(q)), nil
+}
+
+func (r Rat) Mod(a, b *big.Rat) (*big.Rat, error) {
+	if b.Sign() == 0 {
+		return nil, ErrDivByZero
+	}
+	m := new(big.Rat).Abs(b)
+	q := new(big.Rat).SetInt(floor(new(big.Rat).Quo(a, m)))
+	return new(big.Rat).Sub(a, q.Mul(q, m)), nil
+}
+
+// floor rounds `x` down to the nearest integer
+func floor(x *big.Rat) *big.Int {
+	// Num/Denom always has a positive denominator, so Euclidean division rounds down
+	return new(big.Int).Div(x.Num(), x.Denom())
+}
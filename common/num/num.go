@@ -0,0 +1,147 @@
+// Package num lets solutions run on different number backends, so that the same
+// code can use fast machine integers, or exact arbitrary-precision numbers on
+// inputs where those would overflow or lose precision
+package num
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// EnvVar is the environment variable used to pick a backend at run time. Setting
+// it to "big" selects the arbitrary-precision backends
+const EnvVar = "AOC_NUM"
+
+var (
+	// ErrInexact is returned when dividing integers that don't divide evenly
+	ErrInexact = errors.New("inexact division")
+	// ErrDivByZero is returned when dividing or taking a modulo by zero
+	ErrDivByZero = errors.New("division by zero")
+)
+
+// UseBig returns whether the arbitrary-precision backends were requested via `EnvVar`
+func UseBig() bool {
+	return os.Getenv(EnvVar) == "big"
+}
+
+// Arith is a number backend for values of type T. Operations report problems
+// as errors, rather than silently producing a wrong result
+type Arith[T any] interface {
+	// Name identifies the backend, for logging
+	Name() string
+	FromInt(x int) T
+	Parse(s string) (T, error)
+	Add(a, b T) (T, error)
+	Sub(a, b T) (T, error)
+	Mul(a, b T) (T, error)
+	// Div is exact division; integer backends return `ErrInexact` if `b` doesn't divide `a`
+	Div(a, b T) (T, error)
+	// FloorDiv divides and rounds down to the nearest integer
+	FloorDiv(a, b T) (T, error)
+	// Mod returns `a` modulo `b`, which is always in the range [0, |b|)
+	Mod(a, b T) (T, error)
+	Cmp(a, b T) int
+	String(x T) string
+}
+
+// Must returns `x`, or panics if `err` is set. It's meant for wrapping `Arith` calls
+// in solutions, where a failed operation means the answer can't be trusted
+func Must[T any](x T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return x
+}
+
+// Pow returns x**y using `a`. `y` must be non-negative
+func Pow[T any](a Arith[T], x T, y int) (T, error) {
+	if y < 0 {
+		return *new(T), fmt.Errorf("negative exponent %v", y)
+	}
+
+	result := a.FromInt(1)
+	var err error
+	for y > 0 {
+		if y&1 == 1 {
+			if result, err = a.Mul(result, x); err != nil {
+				return *new(T), err
+			}
+		}
+		if y >>= 1; y > 0 {
+			if x, err = a.Mul(x, x); err != nil {
+				return *new(T), err
+			}
+		}
+	}
+	return result, nil
+}
+
+// Sum returns the total of `vals` using `a`
+func Sum[T any](a Arith[T], vals []T) (T, error) {
+	total := a.FromInt(0)
+	var err error
+	for _, v := range vals {
+		if total, err = a.Add(total, v); err != nil {
+			return *new(T), err
+		}
+	}
+	return total, nil
+}
+
+// GCD returns the greatest common divisor of all `vals` using `a`, which is always
+// non-negative. The GCD of no values is 0
+func GCD[T any](a Arith[T], vals ...T) (T, error) {
+	zero := a.FromInt(0)
+	result := zero
+	for _, y := range vals {
+		x := result
+		for a.Cmp(y, zero) != 0 {
+			r, err := a.Mod(x, y)
+			if err != nil {
+				return *new(T), err
+			}
+			x, y = y, r
+		}
+		var err error
+		if result, err = abs(a, x); err != nil {
+			return *new(T), err
+		}
+	}
+	return result, nil
+}
+
+// LCM returns the least common multiple of all `vals` using `a`. The LCM of no values is 1
+func LCM[T any](a Arith[T], vals ...T) (T, error) {
+	zero := a.FromInt(0)
+	result := a.FromInt(1)
+	for _, v := range vals {
+		if a.Cmp(v, zero) == 0 {
+			return zero, nil
+		}
+		v, err := abs(a, v)
+		if err != nil {
+			return *new(T), err
+		}
+
+		g, err := GCD(a, result, v)
+		if err != nil {
+			return *new(T), err
+		}
+		if result, err = a.Div(result, g); err != nil {
+			return *new(T), err
+		}
+		if result, err = a.Mul(result, v); err != nil {
+			return *new(T), err
+		}
+	}
+	return result, nil
+}
+
+func abs[T any](a Arith[T], x T) (T, error) {
+	zero := a.FromInt(0)
+	if a.Cmp(x, zero) < 0 {
+		return a.Sub(zero, x)
+	}
+	return x, nil
+}
@@ -0,0 +1,36 @@
+package parse
+
+import "fmt"
+
+// Error describes where in the input a parse failure happened. `Line` and `Col` are
+// 1-based, and are left as 0 when unknown (e.g. a single line has no line number)
+type Error struct {
+	Line int
+	Col  int
+	Err  error
+}
+
+func (e *Error) Error() string {
+	switch {
+	case e.Line > 0 && e.Col > 0:
+		return fmt.Sprintf("line %d, col %d: %v", e.Line, e.Col, e.Err)
+	case e.Line > 0:
+		return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+	case e.Col > 0:
+		return fmt.Sprintf("col %d: %v", e.Col, e.Err)
+	default:
+		return e.Err.Error()
+	}
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// atLine returns `err` positioned at `line`, keeping any column it already had
+func atLine(err error, line int) error {
+	if pe, ok := err.(*Error); ok {
+		return &Error{Line: line, Col: pe.Col, Err: pe.Err}
+	}
+	return &Error{Line: line, Err: err}
+}
@@ -0,0 +1,276 @@
+package parse
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ShajeshJ/adventofcode_2022/common/util"
+)
+
+// Pattern matches text against a template such as
+//
+//	Valve {id} has flow rate={rate}; [tunnels lead|tunnel leads] to valve[s] {leads}
+//
+// where each `{name}` placeholder captures text into the struct field tagged with
+// `parse:"name"`. The field's type decides how the text is converted: strings are kept
+// as-is, numbers are parsed, and slices are split on commas. A `{_}` placeholder matches
+// text without storing it.
+//
+// `[a|b]` matches exactly one of the alternatives, and `[a]` with a single
+// alternative matches optional text. Alternatives are plain text, without
+// placeholders. A backslash makes the character after it literal, e.g. `\[`.
+// Everything else must match exactly
+type Pattern struct {
+	source   string
+	regex    *regexp.Regexp
+	prefixes []*regexp.Regexp // prefixes[i] matches the pattern up to the end of segment i
+	names    []string
+}
+
+var placeholderRegex = regexp.MustCompile(`^\{(\w+)\}`)
+
+// Compile parses `pattern` into a reusable Pattern
+func Compile(pattern string) (*Pattern, error) {
+	p := &Pattern{source: pattern}
+
+	var builder, literal strings.Builder
+	builder.WriteString(`(?s)^`)
+	flush := func() {
+		builder.WriteString(regexp.QuoteMeta(literal.String()))
+		literal.Reset()
+	}
+	addPrefix := func() {
+		p.prefixes = append(p.prefixes, regexp.MustCompile(builder.String()))
+	}
+
+	afterPlaceholder := false
+	for i := 0; i < len(pattern); {
+		switch c := pattern[i]; {
+		case c == '\\':
+			if i+1 == len(pattern) {
+				return nil, fmt.Errorf("pattern %q ends with an unfinished escape", pattern)
+			}
+			literal.WriteByte(pattern[i+1])
+			i += 2
+
+		case c == '{' && placeholderRegex.MatchString(pattern[i:]):
+			if afterPlaceholder && literal.Len() == 0 {
+				return nil, fmt.Errorf("pattern %q has adjacent placeholders at col %d", pattern, i+1)
+			}
+			flush()
+			addPrefix()
+
+			loc := placeholderRegex.FindStringSubmatchIndex(pattern[i:])
+			p.names = append(p.names, pattern[i+loc[2]:i+loc[3]])
+			builder.WriteString(`(.*?)`)
+			i += loc[1]
+			afterPlaceholder = true
+			continue
+
+		case c == '[':
+			alts, end, err := parseGroup(pattern, i)
+			if err != nil {
+				return nil, err
+			}
+			flush()
+			quoted := util.Map(alts, regexp.QuoteMeta)
+			if len(alts) == 1 {
+				builder.WriteString(`(?:` + quoted[0] + `)?`)
+			} else {
+				builder.WriteString(`(?:` + strings.Join(quoted, "|") + `)`)
+			}
+			i = end
+
+		case c == ']' || c == '|':
+			return nil, fmt.Errorf("pattern %q has an unexpected %q at col %d", pattern, c, i+1)
+
+		default:
+			literal.WriteByte(c)
+			i++
+		}
+		afterPlaceholder = false
+	}
+	flush()
+	addPrefix()
+	builder.WriteString(`$`)
+
+	var err error
+	if p.regex, err = regexp.Compile(builder.String()); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// parseGroup reads the `[a|b]` group that opens at `start`, returning its
+// alternatives and the index just past its closing bracket
+func parseGroup(pattern string, start int) ([]string, int, error) {
+	var alts []string
+	var alt strings.Builder
+	for i := start + 1; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				alt.WriteByte(pattern[i])
+			}
+		case '|':
+			alts = append(alts, alt.String())
+			alt.Reset()
+		case ']':
+			return append(alts, alt.String()), i + 1, nil
+		case '[', '{':
+			return nil, 0, fmt.Errorf("pattern %q has a nested %q at col %d; escape it to match it literally", pattern, c, i+1)
+		default:
+			alt.WriteByte(c)
+		}
+	}
+	return nil, 0, fmt.Errorf("pattern %q has an unclosed '[' at col %d", pattern, start+1)
+}
+
+// MustCompile is like Compile but panics if the pattern is invalid
+func MustCompile(pattern string) *Pattern {
+	p, err := Compile(pattern)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+func (p *Pattern) String() string {
+	return p.source
+}
+
+// Match matches `s` against the pattern, and stores the captured values in the
+// struct pointed to by `dest`
+func (p *Pattern) Match(s string, dest any) error {
+	fields, err := taggedFields(dest)
+	if err != nil {
+		return err
+	}
+
+	loc := p.regex.FindStringSubmatchIndex(s)
+	if loc == nil {
+		return &Error{Col: p.mismatchCol(s), Err: fmt.Errorf("text does not match pattern %q", p.source)}
+	}
+
+	for i, name := range p.names {
+		if name == "_" {
+			continue
+		}
+		field, ok := fields[name]
+		if !ok {
+			return fmt.Errorf("no field tagged `parse:%q` in %T", name, dest)
+		}
+
+		start, end := loc[2*i+2], loc[2*i+3]
+		if err := setField(field, s[start:end]); err != nil {
+			return &Error{Col: start + 1, Err: fmt.Errorf("{%s}: %w", name, err)}
+		}
+	}
+	return nil
+}
+
+// mismatchCol returns the 1-based column just past the longest part of the pattern that `s` satisfies
+func (p *Pattern) mismatchCol(s string) int {
+	col := 1
+	for _, prefix := range p.prefixes {
+		loc := prefix.FindStringIndex(s)
+		if loc == nil {
+			break
+		}
+		col = loc[1] + 1
+	}
+	return col
+}
+
+// Match is a shorthand to compile `pattern` and match it against `s`
+func Match(pattern, s string, dest any) error {
+	p, err := Compile(pattern)
+	if err != nil {
+		return err
+	}
+	return p.Match(s, dest)
+}
+
+// MatchLines matches every line against `pattern`, returning one T per line. Like
+// `Grid`, a trailing blank line is ignored. Errors are positioned at the line that
+// failed to match
+func MatchLines[T any](pattern *Pattern, lines []string) ([]T, error) {
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	output := make([]T, len(lines))
+	for i, line := range lines {
+		if err := pattern.Match(line, &output[i]); err != nil {
+			return nil, atLine(err, i+1)
+		}
+	}
+	return output, nil
+}
+
+func taggedFields(dest any) (map[string]reflect.Value, error) {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("destination must be a pointer to a struct, got %T", dest)
+	}
+
+	v = v.Elem()
+	fields := map[string]reflect.Value{}
+	for i := 0; i < v.NumField(); i++ {
+		if tag, ok := v.Type().Field(i).Tag.Lookup("parse"); ok {
+			fields[tag] = v.Field(i)
+		}
+	}
+	return fields, nil
+}
+
+func setField(field reflect.Value, text string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(text)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		val, err := strconv.ParseInt(strings.TrimSpace(text), 10, field.Type().Bits())
+		if err != nil {
+			return unwrapNumError(err)
+		}
+		field.SetInt(val)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		val, err := strconv.ParseUint(strings.TrimSpace(text), 10, field.Type().Bits())
+		if err != nil {
+			return unwrapNumError(err)
+		}
+		field.SetUint(val)
+	case reflect.Float32, reflect.Float64:
+		val, err := strconv.ParseFloat(strings.TrimSpace(text), field.Type().Bits())
+		if err != nil {
+			return unwrapNumError(err)
+		}
+		field.SetFloat(val)
+	case reflect.Slice:
+		items := strings.Split(text, ",")
+		slice := reflect.MakeSlice(field.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setField(slice.Index(i), strings.TrimSpace(item)); err != nil {
+				return fmt.Errorf("item %d: %w", i, err)
+			}
+		}
+		field.Set(slice)
+	default:
+		return fmt.Errorf("unsupported field type %v", field.Type())
+	}
+	return nil
+}
+
+// unwrapNumError drops the redundant function name from strconv errors
+func unwrapNumError(err error) error {
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		return fmt.Errorf("%q: %w", numErr.Num, numErr.Err)
+	}
+	return err
+}
@@ -0,0 +1,97 @@
+package parse
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type valve struct {
+	ID      string   `parse:"id"`
+	Rate    int      `parse:"rate"`
+	LeadsTo []string `parse:"leads"`
+}
+
+var valvePattern = MustCompile("Valve {id} has flow rate={rate}; [tunnels lead|tunnel leads] to valve[s] {leads}")
+
+func TestPatternGroups(t *testing.T) {
+	tests := []struct {
+		line string
+		want valve
+	}{
+		{"Valve AA has flow rate=0; tunnels lead to valves DD, II, BB", valve{"AA", 0, []string{"DD", "II", "BB"}}},
+		{"Valve HH has flow rate=22; tunnel leads to valve GG", valve{"HH", 22, []string{"GG"}}},
+	}
+
+	for _, tt := range tests {
+		var got valve
+		if err := valvePattern.Match(tt.line, &got); err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Match(%q) = %+v, %v, want %+v", tt.line, got, err, tt.want)
+		}
+	}
+
+	// Alternatives must match exactly one of them, and can't be mixed up
+	var v valve
+	for _, line := range []string{
+		"Valve AA has flow rate=0; tunnels leads to valves DD",
+		"Valve AA has flow rate=0; to valves DD",
+	} {
+		if err := valvePattern.Match(line, &v); err == nil {
+			t.Errorf("Match(%q) succeeded, want an error", line)
+		}
+	}
+}
+
+func TestPatternEscapes(t *testing.T) {
+	var dest struct {
+		Val int `parse:"val"`
+	}
+	if err := Match(`\[{val}\] \{x\} a\|b`, "[7] {x} a|b", &dest); err != nil || dest.Val != 7 {
+		t.Errorf("Match with escapes = %v, %v, want 7", dest.Val, err)
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"{a}{b}", "adjacent placeholders"},
+		{"a [b|c", "unclosed '['"},
+		{"a [b [c]]", "nested '['"},
+		{"a [{b}]", "nested '{'"},
+		{"a ] b", "unexpected ']'"},
+		{"a | b", "unexpected '|'"},
+		{`a \`, "unfinished escape"},
+	}
+
+	for _, tt := range tests {
+		if _, err := Compile(tt.pattern); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Compile(%q) error = %v, want %q", tt.pattern, err, tt.want)
+		}
+	}
+}
+
+func TestMatchLines(t *testing.T) {
+	lines := []string{
+		"Valve AA has flow rate=0; tunnels lead to valves DD, BB",
+		"Valve BB has flow rate=13; tunnel leads to valve AA",
+		"",
+	}
+	got, err := MatchLines[valve](valvePattern, lines)
+	if err != nil || len(got) != 2 {
+		t.Fatalf("MatchLines with a trailing blank line = %v, %v", got, err)
+	}
+
+	_, err = MatchLines[valve](valvePattern, append([]string{"Valve AA"}, lines...))
+	var parseErr *Error
+	if !errors.As(err, &parseErr) || parseErr.Line != 1 {
+		t.Errorf("MatchLines error = %v, want an error at line 1", err)
+	}
+
+	// Blank lines before the end are still an error
+	if _, err := MatchLines[valve](valvePattern, []string{"", lines[0]}); err == nil {
+		t.Error("MatchLines with a leading blank line succeeded, want an error")
+	}
+}
@@ -0,0 +1,89 @@
+package parse
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+var intRegex = regexp.MustCompile(`-?\d+`)
+
+// Blocks splits `lines` into groups separated by blank lines. Consecutive blank lines,
+// as well as leading and trailing ones, don't produce empty blocks
+func Blocks(lines []string) [][]string {
+	var blocks [][]string
+	var cur []string
+	for _, line := range lines {
+		if line == "" {
+			if len(cur) > 0 {
+				blocks = append(blocks, cur)
+				cur = nil
+			}
+			continue
+		}
+		cur = append(cur, line)
+	}
+	if len(cur) > 0 {
+		blocks = append(blocks, cur)
+	}
+	return blocks
+}
+
+// Ints extracts every (optionally negative) integer in `s`, in the order they appear.
+// A '-' straight after a digit is treated as a separator rather than a sign, so
+// "2-4" is 2 and 4, while "x=-4" is -4
+func Ints(s string) ([]int, error) {
+	var ints []int
+	for _, loc := range intRegex.FindAllStringIndex(s, -1) {
+		start := loc[0]
+		if s[start] == '-' && start > 0 && isDigit(s[start-1]) {
+			start++
+		}
+
+		val, err := strconv.Atoi(s[start:loc[1]])
+		if err != nil {
+			return nil, &Error{Col: start + 1, Err: err}
+		}
+		ints = append(ints, val)
+	}
+	return ints, nil
+}
+
+func isDigit(b byte) bool {
+	return '0' <= b && b <= '9'
+}
+
+// Grid converts `lines` into a 2D grid of runes indexed by [row][col]. Every row must
+// be the same length; a trailing blank line is ignored
+func Grid(lines []string) ([][]rune, error) {
+	return GridFunc(lines, func(r rune, row, col int) (rune, error) { return r, nil })
+}
+
+// GridFunc converts `lines` into a 2D grid indexed by [row][col], using `convert` to turn
+// each character into a cell. Every row must be the same length; a trailing blank line is ignored
+func GridFunc[T any](lines []string, convert func(r rune, row, col int) (T, error)) ([][]T, error) {
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	grid := make([][]T, len(lines))
+	for row, line := range lines {
+		runes := []rune(line)
+		if row > 0 && len(runes) != len(grid[0]) {
+			return nil, &Error{
+				Line: row + 1,
+				Err:  fmt.Errorf("row has width %d, expected %d", len(runes), len(grid[0])),
+			}
+		}
+
+		grid[row] = make([]T, len(runes))
+		for col, r := range runes {
+			cell, err := convert(r, row, col)
+			if err != nil {
+				return nil, &Error{Line: row + 1, Col: col + 1, Err: err}
+			}
+			grid[row][col] = cell
+		}
+	}
+	return grid, nil
+}
@@ -0,0 +1,39 @@
+package parse
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestInts(t *testing.T) {
+	tests := []struct {
+		s    string
+		want []int
+	}{
+		{"", nil},
+		{"no numbers", nil},
+		{"Sensor at x=2, y=-18: closest beacon is at x=-2, y=15", []int{2, -18, -2, 15}},
+		// A '-' between digits is a range separator, not a sign
+		{"2-4,6-8", []int{2, 4, 6, 8}},
+		{"-3", []int{-3}},
+		{"1,-3", []int{1, -3}},
+		{"10--3", []int{10, -3}},
+		{"move 12 from 3 to 9", []int{12, 3, 9}},
+	}
+
+	for _, tt := range tests {
+		if got, err := Ints(tt.s); err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Ints(%q) = %v, %v, want %v", tt.s, got, err, tt.want)
+		}
+	}
+}
+
+func TestIntsOutOfRange(t *testing.T) {
+	_, err := Ints("1 99999999999999999999")
+	var pe *Error
+	if !errors.As(err, &pe) || pe.Col != 3 || !errors.Is(err, strconv.ErrRange) {
+		t.Errorf("Ints with an out of range number returned %v, want a range error at column 3", err)
+	}
+}
@@ -0,0 +1,69 @@
+// Package pool runs independent jobs across a bounded number of goroutines
+package pool
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// Map calls `fn` on every item using at most `workers` goroutines, and returns the
+// results in the same order as `items`. A non-positive `workers` uses one per CPU.
+// If any call fails, or `ctx` is cancelled, the remaining items are skipped, the
+// context passed to the calls still running is cancelled, and the first error is returned
+func Map[T any, R any](ctx context.Context, items []T, workers int, fn func(ctx context.Context, item T) (R, error)) ([]R, error) {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]R, len(items))
+	jobs := make(chan int)
+
+	var once sync.Once
+	var firstErr error
+	fail := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(items); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				r, err := fn(ctx, items[i])
+				if err != nil {
+					fail(err)
+					continue
+				}
+				results[i] = r
+			}
+		}()
+	}
+
+feed:
+	for i := range items {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		// Cancelled by the caller, rather than by a failed call
+		return nil, err
+	}
+	return results, nil
+}
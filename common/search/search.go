@@ -0,0 +1,179 @@
+package search
+
+import (
+	"container/heap"
+
+	ds "github.com/ShajeshJ/adventofcode_2022/common/datastructures"
+)
+
+// Order decides which state the engine expands next
+type Order int
+
+const (
+	// DepthFirst expands the most recently generated state first. It uses little
+	// memory, and quickly finds complete solutions to prune with
+	DepthFirst Order = iota
+	// BestFirst expands the state with the highest bound first
+	BestFirst
+)
+
+// Problem describes a maximisation problem over states of type S. States which are
+// considered equivalent share a key of type K
+type Problem[S any, K comparable] struct {
+	Initial S
+
+	// Successors returns the states reachable from `s` in a single step, in the
+	// order they should be explored. Terminal states return no successors
+	Successors func(s S) []S
+
+	// Objective returns the value of `s` if the search stopped there
+	Objective func(s S) int
+
+	// Bound returns an optimistic estimate of the best objective reachable from `s`.
+	// It must never be lower than the real best, or the search may prune the optimum
+	Bound func(s S) int
+
+	// Key identifies equivalent states, so each one is only expanded once. If nil,
+	// every state is expanded
+	Key func(s S) K
+
+	// Dominates reports whether `a` is at least as good as `b`, where both have the same
+	// key. When set, a state is expanded again if it's strictly better than the previously
+	// expanded state with its key, i.e. it dominates that state but not the other way
+	// around; otherwise only the first state with each key is expanded
+	Dominates func(a, b S) bool
+}
+
+// Options configures how the engine searches
+type Options struct {
+	Order Order
+
+	// MaxNodes stops the search after expanding this many states. A non-positive
+	// value means there is no limit
+	MaxNodes int
+}
+
+// Stats describes how much work a search did
+type Stats struct {
+	Expanded   int  // States whose successors were generated
+	Pruned     int  // States skipped because their bound couldn't beat the best
+	Duplicates int  // States skipped because an equivalent state was already expanded
+	Truncated  bool // Whether the search stopped early due to `MaxNodes`
+}
+
+// Result is the outcome of a search
+type Result[S any] struct {
+	Best  S
+	Value int
+	Stats Stats
+}
+
+// Maximize searches the problem's state space for the state with the highest objective.
+// Branches whose bound can't beat the best state found so far are pruned
+func Maximize[S any, K comparable](p Problem[S, K], opts Options) Result[S] {
+	result := Result[S]{Best: p.Initial, Value: p.Objective(p.Initial)}
+	seen := map[K]S{}
+
+	f := newFrontier[S](opts.Order, p.Bound)
+	f.push(p.Initial)
+
+	for f.len() > 0 {
+		s := f.pop()
+
+		if p.Bound(s) <= result.Value {
+			result.Stats.Pruned++
+			continue
+		}
+
+		if p.Key != nil {
+			k := p.Key(s)
+			if prev, ok := seen[k]; ok && !strictlyBetter(p, s, prev) {
+				result.Stats.Duplicates++
+				continue
+			}
+			seen[k] = s
+		}
+
+		if opts.MaxNodes > 0 && result.Stats.Expanded >= opts.MaxNodes {
+			result.Stats.Truncated = true
+			break
+		}
+		result.Stats.Expanded++
+
+		next := p.Successors(s)
+		for _, n := range next {
+			if v := p.Objective(n); v > result.Value {
+				result.Best, result.Value = n, v
+			}
+		}
+
+		// Push in reverse, so that depth-first pops successors in their given order
+		for i := len(next) - 1; i >= 0; i-- {
+			if p.Bound(next[i]) <= result.Value {
+				result.Stats.Pruned++
+				continue
+			}
+			f.push(next[i])
+		}
+	}
+
+	return result
+}
+
+// strictlyBetter returns whether `a` should be expanded even though `b`, with the
+// same key, already has been. Equal states are duplicates, so they never are
+func strictlyBetter[S any, K comparable](p Problem[S, K], a, b S) bool {
+	return p.Dominates != nil && p.Dominates(a, b) && !p.Dominates(b, a)
+}
+
+// frontier holds the states that are waiting to be expanded
+type frontier[S any] interface {
+	push(s S)
+	pop() S
+	len() int
+}
+
+func newFrontier[S any](order Order, bound func(S) int) frontier[S] {
+	if order == BestFirst {
+		return &priorityFrontier[S]{bound: bound}
+	}
+	return &stackFrontier[S]{}
+}
+
+type stackFrontier[S any] struct {
+	stack ds.Stack[S]
+}
+
+func (f *stackFrontier[S]) push(s S) { f.stack.Push(s) }
+func (f *stackFrontier[S]) len() int { return f.stack.Len() }
+func (f *stackFrontier[S]) pop() S {
+	s, _ := f.stack.Pop()
+	return s
+}
+
+type boundedState[S any] struct {
+	state S
+	bound int
+}
+
+// priorityFrontier is a max-heap of states ordered by their bound
+type priorityFrontier[S any] struct {
+	items []boundedState[S]
+	bound func(S) int
+}
+
+func (f *priorityFrontier[S]) push(s S) { heap.Push(f, boundedState[S]{s, f.bound(s)}) }
+func (f *priorityFrontier[S]) len() int { return len(f.items) }
+func (f *priorityFrontier[S]) pop() S   { return heap.Pop(f).(boundedState[S]).state }
+
+// heap.Interface methods; not meant to be called directly
+
+func (f *priorityFrontier[S]) Len() int           { return len(f.items) }
+func (f *priorityFrontier[S]) Less(i, j int) bool { return f.items[i].bound > f.items[j].bound }
+func (f *priorityFrontier[S]) Swap(i, j int)      { f.items[i], f.items[j] = f.items[j], f.items[i] }
+func (f *priorityFrontier[S]) Push(x any)         { f.items = append(f.items, x.(boundedState[S])) }
+func (f *priorityFrontier[S]) Pop() any {
+	last := f.items[len(f.items)-1]
+	f.items = f.items[:len(f.items)-1]
+	return last
+}
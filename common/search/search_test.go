@@ -0,0 +1,81 @@
+package search
+
+import "testing"
+
+type chainState struct {
+	depth int
+	score int
+}
+
+// chainProblem is a search where every state leads to the next depth in one or
+// more ways. Without removing duplicates, the number of expansions grows
+// exponentially with the depth
+func chainProblem(maxDepth int, successors func(s chainState) []chainState) Problem[chainState, int] {
+	return Problem[chainState, int]{
+		Initial: chainState{},
+		Successors: func(s chainState) []chainState {
+			if s.depth == maxDepth {
+				return nil
+			}
+			return successors(s)
+		},
+		Objective: func(s chainState) int { return s.score },
+		Bound:     func(s chainState) int { return 1 << 30 },
+		Key:       func(s chainState) int { return s.depth },
+	}
+}
+
+func TestMaximizeExpansions(t *testing.T) {
+	twoEqual := func(s chainState) []chainState {
+		n := chainState{s.depth + 1, s.score}
+		return []chainState{n, n}
+	}
+	betterSecond := func(s chainState) []chainState {
+		if s.depth == 0 {
+			return []chainState{{1, 0}, {1, 5}}
+		}
+		return []chainState{{s.depth + 1, s.score}}
+	}
+	atLeastAsGood := func(a, b chainState) bool { return a.score >= b.score }
+
+	tests := []struct {
+		name       string
+		problem    Problem[chainState, int]
+		dominates  func(a, b chainState) bool
+		noKey      bool
+		order      Order
+		expanded   int
+		duplicates int
+		value      int
+	}{
+		{"no key expands every path", chainProblem(10, twoEqual), nil, true, DepthFirst, 1<<11 - 1, 0, 0},
+		{"key drops duplicates", chainProblem(20, twoEqual), nil, false, DepthFirst, 21, 20, 0},
+		{"equal states aren't re-expanded", chainProblem(20, twoEqual), atLeastAsGood, false, DepthFirst, 21, 20, 0},
+		{"equal states aren't re-expanded best first", chainProblem(20, twoEqual), atLeastAsGood, false, BestFirst, 21, 20, 0},
+		// Without dominance, the second state at depth 1 is a duplicate despite its higher
+		// score, so only its own objective counts and its successors are never explored
+		{"better state is a duplicate without dominance", chainProblem(3, betterSecond), nil, false, DepthFirst, 4, 1, 5},
+		{"better state is re-expanded", chainProblem(3, betterSecond), atLeastAsGood, false, DepthFirst, 7, 0, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.problem
+			p.Dominates = tt.dominates
+			if tt.noKey {
+				p.Key = nil
+			}
+
+			result := Maximize(p, Options{Order: tt.order})
+			if result.Stats.Expanded != tt.expanded {
+				t.Errorf("Expanded = %d, want %d", result.Stats.Expanded, tt.expanded)
+			}
+			if result.Stats.Duplicates != tt.duplicates {
+				t.Errorf("Duplicates = %d, want %d", result.Stats.Duplicates, tt.duplicates)
+			}
+			if result.Value != tt.value {
+				t.Errorf("Value = %d, want %d", result.Value, tt.value)
+			}
+		})
+	}
+}
@@ -1,41 +1,237 @@
 package util
 
-import "math"
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+
+	"golang.org/x/exp/constraints"
+)
+
+// ErrOverflow is returned by the checked arithmetic functions when the
+// result can't be represented by the integer type
+var ErrOverflow = errors.New("integer overflow")
 
 // Abs returns the absolute value of x.
-func Abs(v int) int {
-	return int(math.Abs(float64(v)))
+func Abs[T constraints.Integer](x T) T {
+	if x < 0 {
+		return -x
+	}
+	return x
 }
 
 // Min returns the smaller of x or y.
-func Min(x int, y int) int {
-	return int(math.Min(float64(x), float64(y)))
+func Min[T constraints.Integer](x, y T) T {
+	if x < y {
+		return x
+	}
+	return y
 }
 
 // Max returns the larger of x or y.
-func Max(x int, y int) int {
-	return int(math.Max(float64(x), float64(y)))
+func Max[T constraints.Integer](x, y T) T {
+	if x > y {
+		return x
+	}
+	return y
 }
 
-// Pow returns x**y, the base-x exponential of y.
-func Pow(x, y int) int {
-	return int(math.Pow(float64(x), float64(y)))
+// Pow returns x**y, the base-x exponential of y. `y` must be non-negative.
+func Pow[T constraints.Integer](x, y T) T {
+	if y < 0 {
+		panic(fmt.Sprintf("negative exponent %v", y))
+	}
+
+	var result T = 1
+	for y > 0 {
+		if y&1 == 1 {
+			result *= x
+		}
+		x *= x
+		y >>= 1
+	}
+	return result
 }
 
-func Normalize(x int) int {
-	if x == 0 {
+// Normalize returns -1, 0 or 1 depending on the sign of x.
+func Normalize[T constraints.Signed](x T) T {
+	switch {
+	case x > 0:
+		return 1
+	case x < 0:
+		return -1
+	default:
 		return 0
 	}
-	return x / int(math.Abs(float64(x)))
 }
 
-func GCD(a, b int) int {
-	if b == 0 {
-		return a
+// GCD returns the greatest common divisor of all `vals`, which is always non-negative.
+// The GCD of no values is 0.
+func GCD[T constraints.Integer](vals ...T) T {
+	var result T
+	for _, b := range vals {
+		a := result
+		b = Abs(b)
+		for b != 0 {
+			a, b = b, a%b
+		}
+		result = a
+	}
+	return result
+}
+
+// LCM returns the least common multiple of all `vals`. The LCM of no values is 1.
+// The result silently overflows if it doesn't fit in T; use `CheckedLCM` if that's a concern.
+func LCM[T constraints.Integer](vals ...T) T {
+	var result T = 1
+	for _, v := range vals {
+		if v == 0 {
+			return 0
+		}
+		result = result / GCD(result, v) * Abs(v)
+	}
+	return result
+}
+
+// CheckedLCM is the same as `LCM`, but returns `ErrOverflow` if the result doesn't fit in T.
+func CheckedLCM[T constraints.Integer](vals ...T) (T, error) {
+	var result T = 1
+	for _, v := range vals {
+		if v == 0 {
+			return 0, nil
+		}
+		var err error
+		if result, err = CheckedMul(result/GCD(result, v), Abs(v)); err != nil {
+			return 0, err
+		}
+	}
+	return result, nil
+}
+
+// Mod returns x modulo m, which unlike `%` is always in the range [0, |m|).
+func Mod[T constraints.Integer](x, m T) T {
+	m = Abs(m)
+	r := x % m
+	if r < 0 {
+		r += m
+	}
+	return r
+}
+
+// MulMod returns (x*y) mod m, without overflowing on the intermediate product.
+func MulMod[T constraints.Integer](x, y, m T) T {
+	m = Abs(m)
+	hi, lo := bits.Mul64(uint64(Mod(x, m)), uint64(Mod(y, m)))
+	return T(bits.Rem64(hi, lo, uint64(m)))
+}
+
+// PowMod returns (x**y) mod m. `y` must be non-negative.
+func PowMod[T constraints.Integer](x, y, m T) T {
+	if y < 0 {
+		panic(fmt.Sprintf("negative exponent %v", y))
+	}
+
+	result := Mod(1, m)
+	x = Mod(x, m)
+	for y > 0 {
+		if y&1 == 1 {
+			result = MulMod(result, x, m)
+		}
+		x = MulMod(x, x, m)
+		y >>= 1
+	}
+	return result
+}
+
+// extendedGCD returns g = gcd(a, b), along with x and y such that a*x + b*y = g.
+func extendedGCD[T constraints.Signed](a, b T) (g, x, y T) {
+	oldR, r := a, b
+	oldS, s := T(1), T(0)
+	oldT, t := T(0), T(1)
+	for r != 0 {
+		q := oldR / r
+		oldR, r = r, oldR-q*r
+		oldS, s = s, oldS-q*s
+		oldT, t = t, oldT-q*t
+	}
+	return oldR, oldS, oldT
+}
+
+// ModInverse returns the y such that (x*y) mod m == 1. An error is returned
+// if x and m aren't coprime, as no such inverse exists.
+func ModInverse[T constraints.Signed](x, m T) (T, error) {
+	m = Abs(m)
+	g, inv, _ := extendedGCD(Mod(x, m), m)
+	if g != 1 {
+		return 0, fmt.Errorf("%v has no inverse modulo %v", x, m)
+	}
+	return Mod(inv, m), nil
+}
+
+// CRT solves the system of congruences x ≡ remainders[i] (mod moduli[i]) using the
+// Chinese Remainder Theorem. It returns the smallest non-negative solution along with
+// the LCM of the moduli, which is the period of all solutions. The moduli don't need to
+// be coprime, but an error is returned if the congruences contradict each other, or if
+// the result doesn't fit in T.
+func CRT[T constraints.Signed](remainders, moduli []T) (T, T, error) {
+	if len(remainders) != len(moduli) {
+		return 0, 0, fmt.Errorf("got %d remainders but %d moduli", len(remainders), len(moduli))
 	}
-	return GCD(b, a%b)
+
+	var x, period T = 0, 1
+	for i := range moduli {
+		m := Abs(moduli[i])
+		if m == 0 {
+			return 0, 0, fmt.Errorf("modulus at index %d is zero", i)
+		}
+		r := Mod(remainders[i], m)
+
+		// Solve x + period*k ≡ r (mod m) for k
+		g, inv, _ := extendedGCD(period, m)
+		diff := r - Mod(x, m)
+		if diff%g != 0 {
+			return 0, 0, fmt.Errorf("congruence x ≡ %v (mod %v) conflicts with earlier ones", remainders[i], moduli[i])
+		}
+
+		step := m / g
+		k := MulMod(Mod(diff/g, step), Mod(inv, step), step)
+
+		newPeriod, err := CheckedMul(period, step)
+		if err != nil {
+			return 0, 0, err
+		}
+		offset, err := CheckedMul(period, k)
+		if err != nil {
+			return 0, 0, err
+		}
+		if x, err = CheckedAdd(x, offset); err != nil {
+			return 0, 0, err
+		}
+		period = newPeriod
+		x = Mod(x, period)
+	}
+	return x, period, nil
 }
 
-func LCM(a, b int) int {
-	return a * b / GCD(a, b)
+// CheckedAdd returns x+y, or `ErrOverflow` if the result doesn't fit in T.
+func CheckedAdd[T constraints.Integer](x, y T) (T, error) {
+	sum := x + y
+	if (y > 0 && sum < x) || (y < 0 && sum > x) {
+		return 0, fmt.Errorf("%v + %v: %w", x, y, ErrOverflow)
+	}
+	return sum, nil
+}
+
+// CheckedMul returns x*y, or `ErrOverflow` if the result doesn't fit in T.
+func CheckedMul[T constraints.Integer](x, y T) (T, error) {
+	if x == 0 || y == 0 {
+		return 0, nil
+	}
+
+	product := x * y
+	wantNegative := (x < 0) != (y < 0)
+	if product/y != x || (product < 0) != wantNegative {
+		return 0, fmt.Errorf("%v * %v: %w", x, y, ErrOverflow)
+	}
+	return product, nil
 }
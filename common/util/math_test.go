@@ -0,0 +1,221 @@
+package util
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestMod(t *testing.T) {
+	tests := []struct {
+		x, m, want int
+	}{
+		{7, 3, 1},
+		{-7, 3, 2},
+		{7, -3, 1},
+		{-7, -3, 2},
+		{-6, 3, 0},
+		{0, 5, 0},
+		{math.MinInt64, 10, 2},
+	}
+
+	for _, tt := range tests {
+		if got := Mod(tt.x, tt.m); got != tt.want {
+			t.Errorf("Mod(%d, %d) = %d, want %d", tt.x, tt.m, got, tt.want)
+		}
+	}
+}
+
+func TestMulMod(t *testing.T) {
+	tests := []struct {
+		x, y, m, want int64
+	}{
+		{3, 4, 5, 2},
+		{-3, 4, 5, 3},
+		// Products near and beyond 2^63, which would overflow if multiplied directly
+		{math.MaxInt64 - 1, math.MaxInt64 - 2, math.MaxInt64, 2},
+		{1<<62 + 3, 1<<62 + 5, math.MaxInt64, 2305843009213693971},
+		{math.MaxInt64, math.MaxInt64, math.MaxInt64 - 1, 1},
+		{math.MaxInt64 - 1, 3, math.MaxInt64 - 24, 69},
+		{5, 7, 1, 0},
+	}
+
+	for _, tt := range tests {
+		if got := MulMod(tt.x, tt.y, tt.m); got != tt.want {
+			t.Errorf("MulMod(%d, %d, %d) = %d, want %d", tt.x, tt.y, tt.m, got, tt.want)
+		}
+	}
+
+	// Unsigned values near 2^64 work too
+	if got := MulMod[uint64](math.MaxUint64, math.MaxUint64-1, math.MaxUint64-4); got != 12 {
+		t.Errorf("MulMod(2^64-1, 2^64-2, 2^64-5) = %d, want 12", got)
+	}
+}
+
+func TestPowMod(t *testing.T) {
+	tests := []struct {
+		x, y, m, want int64
+	}{
+		{2, 10, 1000, 24},
+		{-2, 3, 5, 2},
+		{3, 0, 7, 1},
+		{3, 0, 1, 0},
+		{2, 63, math.MaxInt64, 1},
+		{3, 1e18, math.MaxInt64, 849845927178902840},
+		{7, math.MaxInt64 - 2, math.MaxInt64, 5010327133260547266},
+	}
+
+	for _, tt := range tests {
+		if got := PowMod(tt.x, tt.y, tt.m); got != tt.want {
+			t.Errorf("PowMod(%d, %d, %d) = %d, want %d", tt.x, tt.y, tt.m, got, tt.want)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("PowMod with a negative exponent didn't panic")
+		}
+	}()
+	PowMod(2, -1, 7)
+}
+
+func TestModInverse(t *testing.T) {
+	tests := []struct {
+		x, m, want int
+		ok         bool
+	}{
+		{3, 7, 5, true},
+		{-3, 7, 2, true},
+		{3, -7, 5, true},
+		{1, 2, 1, true},
+		{4, 8, 0, false},
+		{6, 9, 0, false},
+		{0, 5, 0, false},
+	}
+
+	for _, tt := range tests {
+		got, err := ModInverse(tt.x, tt.m)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("ModInverse(%d, %d) = %d, %v, want %d, ok %v", tt.x, tt.m, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestCRT(t *testing.T) {
+	tests := []struct {
+		name               string
+		remainders, moduli []int
+		x, period          int
+		ok                 bool
+	}{
+		{"coprime", []int{2, 3, 2}, []int{3, 5, 7}, 23, 105, true},
+		{"negative remainder", []int{-1}, []int{5}, 4, 5, true},
+		{"negative modulus", []int{1, 2}, []int{-3, 5}, 7, 15, true},
+		{"not coprime", []int{2, 4}, []int{6, 8}, 20, 24, true},
+		{"repeated modulus", []int{3, 10}, []int{7, 7}, 3, 7, true},
+		{"no congruences", nil, nil, 0, 1, true},
+		{"conflicting", []int{1, 2}, []int{4, 6}, 0, 0, false},
+		{"zero modulus", []int{1}, []int{0}, 0, 0, false},
+		{"mismatched lengths", []int{1, 2}, []int{3}, 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, period, err := CRT(tt.remainders, tt.moduli)
+			if (err == nil) != tt.ok || x != tt.x || period != tt.period {
+				t.Errorf("CRT(%v, %v) = %d, %d, %v, want %d, %d, ok %v",
+					tt.remainders, tt.moduli, x, period, err, tt.x, tt.period, tt.ok)
+			}
+		})
+	}
+
+	// The period of two primes just over 2^16 doesn't fit in an int32
+	if _, _, err := CRT([]int32{1, 2}, []int32{65537, 65539}); !errors.Is(err, ErrOverflow) {
+		t.Errorf("CRT overflowing int32 returned %v, want ErrOverflow", err)
+	}
+}
+
+func TestCheckedAdd(t *testing.T) {
+	tests := []struct {
+		x, y, want int64
+		overflow   bool
+	}{
+		{1, 2, 3, false},
+		{math.MaxInt64, 0, math.MaxInt64, false},
+		{math.MaxInt64, -1, math.MaxInt64 - 1, false},
+		{math.MaxInt64, 1, 0, true},
+		{math.MinInt64, -1, 0, true},
+		{math.MinInt64, math.MaxInt64, -1, false},
+		{math.MinInt64, math.MinInt64, 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := CheckedAdd(tt.x, tt.y)
+		if errors.Is(err, ErrOverflow) != tt.overflow || got != tt.want {
+			t.Errorf("CheckedAdd(%d, %d) = %d, %v, want %d, overflow %v", tt.x, tt.y, got, err, tt.want, tt.overflow)
+		}
+	}
+
+	if _, err := CheckedAdd[uint8](200, 100); !errors.Is(err, ErrOverflow) {
+		t.Errorf("CheckedAdd[uint8](200, 100) returned %v, want ErrOverflow", err)
+	}
+}
+
+func TestCheckedMul(t *testing.T) {
+	tests := []struct {
+		x, y, want int64
+		overflow   bool
+	}{
+		{6, 7, 42, false},
+		{0, math.MinInt64, 0, false},
+		{-1, math.MaxInt64, -math.MaxInt64, false},
+		{1 << 31, 1 << 31, 1 << 62, false},
+		{1 << 32, 1 << 31, 0, true},
+		{math.MaxInt64, 2, 0, true},
+		{math.MinInt64 / 2, 2, math.MinInt64, false},
+		{math.MinInt64, -1, 0, true},
+		{-1, math.MinInt64, 0, true},
+		{math.MinInt64, math.MinInt64, 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := CheckedMul(tt.x, tt.y)
+		if errors.Is(err, ErrOverflow) != tt.overflow || got != tt.want {
+			t.Errorf("CheckedMul(%d, %d) = %d, %v, want %d, overflow %v", tt.x, tt.y, got, err, tt.want, tt.overflow)
+		}
+	}
+
+	if got, err := CheckedMul[int8](-16, 8); err != nil || got != math.MinInt8 {
+		t.Errorf("CheckedMul[int8](-16, 8) = %d, %v, want %d, nil", got, err, math.MinInt8)
+	}
+	if _, err := CheckedMul[int8](16, 8); !errors.Is(err, ErrOverflow) {
+		t.Errorf("CheckedMul[int8](16, 8) returned %v, want ErrOverflow", err)
+	}
+}
+
+func TestCheckedLCM(t *testing.T) {
+	tests := []struct {
+		vals     []int64
+		want     int64
+		overflow bool
+	}{
+		{nil, 1, false},
+		{[]int64{4, 6}, 12, false},
+		{[]int64{-4, 6}, 12, false},
+		{[]int64{3, 0, 5}, 0, false},
+		{[]int64{1 << 40, 1 << 62}, 1 << 62, false},
+		{[]int64{1<<61 - 1, 1<<31 - 1}, 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := CheckedLCM(tt.vals...)
+		if errors.Is(err, ErrOverflow) != tt.overflow || got != tt.want {
+			t.Errorf("CheckedLCM(%v) = %d, %v, want %d, overflow %v", tt.vals, got, err, tt.want, tt.overflow)
+		}
+		if !tt.overflow {
+			if got := LCM(tt.vals...); got != tt.want {
+				t.Errorf("LCM(%v) = %d, want %d", tt.vals, got, tt.want)
+			}
+		}
+	}
+}
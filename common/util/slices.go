@@ -1,9 +1,235 @@
 package util
 
+import "golang.org/x/exp/constraints"
+
+// Number is any type that supports arithmetic operators
+type Number interface {
+	constraints.Integer | constraints.Float
+}
+
+// Pair holds two related values, such as the items at the same index of two zipped slices
+type Pair[A any, B any] struct {
+	First  A
+	Second B
+}
+
 func Map[T any, R any](x []T, mapFunc func(T) R) []R {
-	var output []R
+	output := make([]R, 0, len(x))
 	for _, item := range x {
 		output = append(output, mapFunc(item))
 	}
 	return output
 }
+
+// Filter returns the items of `x` that satisfy `pred`, in their original order
+func Filter[T any](x []T, pred func(T) bool) []T {
+	var output []T
+	for _, item := range x {
+		if pred(item) {
+			output = append(output, item)
+		}
+	}
+	return output
+}
+
+// Partition splits `x` into the items that satisfy `pred`, and the ones that don't
+func Partition[T any](x []T, pred func(T) bool) (matched, rest []T) {
+	for _, item := range x {
+		if pred(item) {
+			matched = append(matched, item)
+		} else {
+			rest = append(rest, item)
+		}
+	}
+	return
+}
+
+// Reduce folds every item of `x` into an accumulator, starting from `initial`
+func Reduce[T any, R any](x []T, initial R, reduceFunc func(acc R, item T) R) R {
+	acc := initial
+	for _, item := range x {
+		acc = reduceFunc(acc, item)
+	}
+	return acc
+}
+
+// Sum returns the total of all items in `x`, or 0 if it's empty
+func Sum[T Number](x []T) T {
+	var total T
+	for _, item := range x {
+		total += item
+	}
+	return total
+}
+
+// Product returns the product of all items in `x`, or 1 if it's empty
+func Product[T Number](x []T) T {
+	var total T = 1
+	for _, item := range x {
+		total *= item
+	}
+	return total
+}
+
+// Chunk splits `x` into consecutive groups of `size` items. The last group will be
+// shorter if `len(x)` isn't a multiple of `size`. The groups share memory with `x`
+func Chunk[T any](x []T, size int) [][]T {
+	if size < 1 {
+		panic("chunk size must be positive")
+	}
+
+	output := make([][]T, 0, (len(x)+size-1)/size)
+	for i := 0; i < len(x); i += size {
+		end := Min(i+size, len(x))
+		output = append(output, x[i:end:end])
+	}
+	return output
+}
+
+// SlidingWindow returns every run of `size` consecutive items in `x`, in order.
+// The windows share memory with `x`
+func SlidingWindow[T any](x []T, size int) [][]T {
+	if size < 1 {
+		panic("window size must be positive")
+	}
+	if len(x) < size {
+		return nil
+	}
+
+	output := make([][]T, 0, len(x)-size+1)
+	for i := 0; i+size <= len(x); i++ {
+		output = append(output, x[i:i+size:i+size])
+	}
+	return output
+}
+
+// Zip pairs up the items at the same index of `a` and `b`. The output is as long
+// as the shorter of the two
+func Zip[A any, B any](a []A, b []B) []Pair[A, B] {
+	output := make([]Pair[A, B], Min(len(a), len(b)))
+	for i := range output {
+		output[i] = Pair[A, B]{a[i], b[i]}
+	}
+	return output
+}
+
+// Flatten concatenates all of the slices in `x` into one
+func Flatten[T any](x [][]T) []T {
+	size := 0
+	for _, inner := range x {
+		size += len(inner)
+	}
+
+	output := make([]T, 0, size)
+	for _, inner := range x {
+		output = append(output, inner...)
+	}
+	return output
+}
+
+// GroupBy buckets the items of `x` by the key returned from `keyFunc`. The
+// items in each bucket keep their original order
+func GroupBy[T any, K comparable](x []T, keyFunc func(T) K) map[K][]T {
+	output := map[K][]T{}
+	for _, item := range x {
+		key := keyFunc(item)
+		output[key] = append(output[key], item)
+	}
+	return output
+}
+
+// CountBy counts how many items of `x` map to each key returned from `keyFunc`
+func CountBy[T any, K comparable](x []T, keyFunc func(T) K) map[K]int {
+	output := map[K]int{}
+	for _, item := range x {
+		output[keyFunc(item)]++
+	}
+	return output
+}
+
+// MinBy returns the first item of `x` with the smallest key, or false if `x` is empty
+func MinBy[T any, K constraints.Ordered](x []T, keyFunc func(T) K) (T, bool) {
+	return bestBy(x, keyFunc, func(a, b K) bool { return a < b })
+}
+
+// MaxBy returns the first item of `x` with the largest key, or false if `x` is empty
+func MaxBy[T any, K constraints.Ordered](x []T, keyFunc func(T) K) (T, bool) {
+	return bestBy(x, keyFunc, func(a, b K) bool { return a > b })
+}
+
+func bestBy[T any, K constraints.Ordered](x []T, keyFunc func(T) K, better func(a, b K) bool) (T, bool) {
+	if len(x) == 0 {
+		return *new(T), false
+	}
+
+	best, bestKey := x[0], keyFunc(x[0])
+	for _, item := range x[1:] {
+		if key := keyFunc(item); better(key, bestKey) {
+			best, bestKey = item, key
+		}
+	}
+	return best, true
+}
+
+// Combinations returns every way of choosing `k` items from `x`, where order doesn't
+// matter. Items keep their relative order from `x` within each combination
+func Combinations[T any](x []T, k int) [][]T {
+	if k < 0 || k > len(x) {
+		return nil
+	}
+
+	var output [][]T
+	indexes := make([]int, k)
+	for i := range indexes {
+		indexes[i] = i
+	}
+
+	for {
+		combo := make([]T, k)
+		for i, idx := range indexes {
+			combo[i] = x[idx]
+		}
+		output = append(output, combo)
+
+		// Find the right-most index that can still be incremented
+		i := k - 1
+		for i >= 0 && indexes[i] == len(x)-k+i {
+			i--
+		}
+		if i < 0 {
+			return output
+		}
+
+		indexes[i]++
+		for j := i + 1; j < k; j++ {
+			indexes[j] = indexes[j-1] + 1
+		}
+	}
+}
+
+// Permutations returns every ordering of the items in `x`, using Heap's algorithm
+func Permutations[T any](x []T) [][]T {
+	perm := make([]T, len(x))
+	copy(perm, x)
+
+	output := [][]T{append([]T{}, perm...)}
+	counters := make([]int, len(perm))
+
+	for i := 1; i < len(perm); {
+		if counters[i] >= i {
+			counters[i] = 0
+			i++
+			continue
+		}
+
+		if i%2 == 0 {
+			perm[0], perm[i] = perm[i], perm[0]
+		} else {
+			perm[counters[i]], perm[i] = perm[i], perm[counters[i]]
+		}
+		output = append(output, append([]T{}, perm...))
+		counters[i]++
+		i = 1
+	}
+	return output
+}
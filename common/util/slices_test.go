@@ -0,0 +1,249 @@
+package util
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestFilterAndPartition(t *testing.T) {
+	isEven := func(x int) bool { return x%2 == 0 }
+	tests := []struct {
+		x             []int
+		matched, rest []int
+	}{
+		{nil, nil, nil},
+		{[]int{1, 3}, nil, []int{1, 3}},
+		{[]int{4, 1, 2, 3, 6}, []int{4, 2, 6}, []int{1, 3}},
+	}
+
+	for _, tt := range tests {
+		if got := Filter(tt.x, isEven); !reflect.DeepEqual(got, tt.matched) {
+			t.Errorf("Filter(%v) = %v, want %v", tt.x, got, tt.matched)
+		}
+		matched, rest := Partition(tt.x, isEven)
+		if !reflect.DeepEqual(matched, tt.matched) || !reflect.DeepEqual(rest, tt.rest) {
+			t.Errorf("Partition(%v) = %v, %v, want %v, %v", tt.x, matched, rest, tt.matched, tt.rest)
+		}
+	}
+}
+
+func TestReduceSumProduct(t *testing.T) {
+	tests := []struct {
+		x            []int
+		sum, product int
+	}{
+		{nil, 0, 1},
+		{[]int{7}, 7, 7},
+		{[]int{2, -3, 4}, 3, -24},
+	}
+
+	for _, tt := range tests {
+		if got := Sum(tt.x); got != tt.sum {
+			t.Errorf("Sum(%v) = %v, want %v", tt.x, got, tt.sum)
+		}
+		if got := Product(tt.x); got != tt.product {
+			t.Errorf("Product(%v) = %v, want %v", tt.x, got, tt.product)
+		}
+		if got := Reduce(tt.x, 0, func(acc, item int) int { return acc + item }); got != tt.sum {
+			t.Errorf("Reduce(%v, +) = %v, want %v", tt.x, got, tt.sum)
+		}
+	}
+
+	joined := Reduce([]int{1, 2, 3}, "", func(acc string, item int) string { return acc + fmt.Sprint(item) })
+	if joined != "123" {
+		t.Errorf("Reduce into a string = %q, want %q", joined, "123")
+	}
+}
+
+func TestChunk(t *testing.T) {
+	tests := []struct {
+		x    []int
+		size int
+		want [][]int
+	}{
+		{[]int{}, 3, [][]int{}},
+		{[]int{1, 2, 3, 4}, 2, [][]int{{1, 2}, {3, 4}}},
+		{[]int{1, 2, 3, 4, 5}, 2, [][]int{{1, 2}, {3, 4}, {5}}},
+		{[]int{1, 2}, 5, [][]int{{1, 2}}},
+	}
+
+	for _, tt := range tests {
+		if got := Chunk(tt.x, tt.size); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Chunk(%v, %d) = %v, want %v", tt.x, tt.size, got, tt.want)
+		}
+	}
+
+	// Appending to a short last chunk mustn't write past it into `x`
+	x := []int{1, 2, 3, 4, 5}
+	chunks := Chunk(x[:3], 2)
+	_ = append(chunks[1], 99)
+	if x[3] != 4 {
+		t.Errorf("appending to a chunk overwrote the original slice: %v", x)
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	tests := []struct {
+		x    []int
+		size int
+		want [][]int
+	}{
+		{[]int{}, 1, nil},
+		{[]int{1, 2}, 3, nil},
+		{[]int{1, 2, 3}, 3, [][]int{{1, 2, 3}}},
+		{[]int{1, 2, 3, 4}, 2, [][]int{{1, 2}, {2, 3}, {3, 4}}},
+	}
+
+	for _, tt := range tests {
+		if got := SlidingWindow(tt.x, tt.size); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SlidingWindow(%v, %d) = %v, want %v", tt.x, tt.size, got, tt.want)
+		}
+	}
+}
+
+func TestZip(t *testing.T) {
+	tests := []struct {
+		a    []int
+		b    []string
+		want []Pair[int, string]
+	}{
+		{nil, []string{"a"}, []Pair[int, string]{}},
+		{[]int{1, 2, 3}, []string{"a", "b"}, []Pair[int, string]{{1, "a"}, {2, "b"}}},
+		{[]int{1}, []string{"a", "b"}, []Pair[int, string]{{1, "a"}}},
+	}
+
+	for _, tt := range tests {
+		if got := Zip(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Zip(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	tests := []struct {
+		x    [][]int
+		want []int
+	}{
+		{nil, []int{}},
+		{[][]int{{}, {1}, nil, {2, 3}}, []int{1, 2, 3}},
+	}
+
+	for _, tt := range tests {
+		if got := Flatten(tt.x); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Flatten(%v) = %v, want %v", tt.x, got, tt.want)
+		}
+	}
+}
+
+func TestGroupByCountBy(t *testing.T) {
+	words := []string{"ant", "bee", "cat", "ox", "dog", "yak", "eel"}
+	byLen := func(s string) int { return len(s) }
+
+	wantGroups := map[int][]string{3: {"ant", "bee", "cat", "dog", "yak", "eel"}, 2: {"ox"}}
+	if got := GroupBy(words, byLen); !reflect.DeepEqual(got, wantGroups) {
+		t.Errorf("GroupBy(len) = %v, want %v", got, wantGroups)
+	}
+
+	wantCounts := map[int]int{3: 6, 2: 1}
+	if got := CountBy(words, byLen); !reflect.DeepEqual(got, wantCounts) {
+		t.Errorf("CountBy(len) = %v, want %v", got, wantCounts)
+	}
+
+	if got := GroupBy([]string{}, byLen); len(got) != 0 {
+		t.Errorf("GroupBy(empty) = %v, want empty", got)
+	}
+}
+
+func TestMinByMaxBy(t *testing.T) {
+	type item struct {
+		name string
+		key  int
+	}
+	key := func(i item) int { return i.key }
+
+	tests := []struct {
+		name     string
+		x        []item
+		min, max item
+		ok       bool
+	}{
+		{"empty", nil, item{}, item{}, false},
+		{"single", []item{{"a", 1}}, item{"a", 1}, item{"a", 1}, true},
+		{"distinct", []item{{"a", 2}, {"b", 1}, {"c", 3}}, item{"b", 1}, item{"c", 3}, true},
+		// Ties go to the first item with the best key
+		{"ties", []item{{"a", 1}, {"b", 3}, {"c", 1}, {"d", 3}}, item{"a", 1}, item{"b", 3}, true},
+		{"all equal", []item{{"a", 5}, {"b", 5}}, item{"a", 5}, item{"a", 5}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, ok := MinBy(tt.x, key); got != tt.min || ok != tt.ok {
+				t.Errorf("MinBy = %v, %v, want %v, %v", got, ok, tt.min, tt.ok)
+			}
+			if got, ok := MaxBy(tt.x, key); got != tt.max || ok != tt.ok {
+				t.Errorf("MaxBy = %v, %v, want %v, %v", got, ok, tt.max, tt.ok)
+			}
+		})
+	}
+}
+
+func TestCombinations(t *testing.T) {
+	tests := []struct {
+		x    []int
+		k    int
+		want [][]int
+	}{
+		{[]int{1, 2, 3}, 0, [][]int{{}}},
+		{[]int{}, 0, [][]int{{}}},
+		{[]int{1, 2, 3}, 4, nil},
+		{[]int{1, 2, 3}, -1, nil},
+		{[]int{1, 2, 3}, 1, [][]int{{1}, {2}, {3}}},
+		{[]int{1, 2, 3}, 2, [][]int{{1, 2}, {1, 3}, {2, 3}}},
+		{[]int{1, 2, 3}, 3, [][]int{{1, 2, 3}}},
+	}
+
+	for _, tt := range tests {
+		if got := Combinations(tt.x, tt.k); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Combinations(%v, %d) = %v, want %v", tt.x, tt.k, got, tt.want)
+		}
+	}
+
+	// C(6, 3) = 20
+	if got := len(Combinations([]int{1, 2, 3, 4, 5, 6}, 3)); got != 20 {
+		t.Errorf("len(Combinations(6 items, 3)) = %d, want 20", got)
+	}
+}
+
+func TestPermutations(t *testing.T) {
+	tests := []struct {
+		x     []int
+		count int
+	}{
+		{[]int{}, 1},
+		{[]int{1}, 1},
+		{[]int{1, 2}, 2},
+		{[]int{1, 2, 3}, 6},
+		{[]int{1, 2, 3, 4, 5}, 120},
+		{[]int{1, 2, 3, 4, 5, 6}, 720},
+	}
+
+	for _, tt := range tests {
+		got := Permutations(tt.x)
+		if len(got) != tt.count {
+			t.Errorf("len(Permutations(%v)) = %d, want %d", tt.x, len(got), tt.count)
+		}
+
+		seen := map[string]bool{}
+		for _, perm := range got {
+			if len(perm) != len(tt.x) || Sum(perm) != Sum(tt.x) {
+				t.Errorf("Permutations(%v) produced %v, which isn't a reordering", tt.x, perm)
+			}
+			if key := fmt.Sprint(perm); seen[key] {
+				t.Errorf("Permutations(%v) produced %v more than once", tt.x, perm)
+			} else {
+				seen[key] = true
+			}
+		}
+	}
+}
@@ -7,7 +7,6 @@ import (
 	ds "github.com/ShajeshJ/adventofcode_2022/common/datastructures"
 	"github.com/ShajeshJ/adventofcode_2022/common/logging"
 	"github.com/ShajeshJ/adventofcode_2022/common/util"
-	"golang.org/x/exp/constraints"
 )
 
 var log = logging.GetLogger()
@@ -15,44 +14,36 @@ var log = logging.GetLogger()
 //go:embed input.txt
 var files embed.FS
 
-func getSum[T constraints.Ordered](l *ds.TopList[T]) T {
-	var total T
-	for _, item := range l.Values {
-		total += item
-	}
-	return total
-}
-
 func PartOne() any {
-	mostCalories := ds.NewTopList[int](1)
+	mostCalories := ds.NewTopKOrdered[int](1)
 	curElfCalories := 0
 
 	for _, food := range util.ReadProblemInput(files) {
 		if food == "" {
-			mostCalories.TryPush(curElfCalories)
+			mostCalories.Push(curElfCalories)
 			curElfCalories = 0
 			continue
 		}
 		curElfCalories += util.AtoiNoError(food)
 	}
 
-	return getSum(mostCalories)
+	return util.Sum(mostCalories.Sorted())
 }
 
 func PartTwo() any {
-	mostCalories := ds.NewTopList[int](3)
+	mostCalories := ds.NewTopKOrdered[int](3)
 	curElfCalories := 0
 
 	for _, food := range util.ReadProblemInput(files) {
 		if food == "" {
-			mostCalories.TryPush(curElfCalories)
+			mostCalories.Push(curElfCalories)
 			curElfCalories = 0
 			continue
 		}
 		curElfCalories += util.AtoiNoError(food)
 	}
 
-	return getSum(mostCalories)
+	return util.Sum(mostCalories.Sorted())
 }
 
 func main() {
@@ -28,14 +28,14 @@ func RunCRT(doCycleProcessing func(cycle, x int)) {
 	cycle := 0
 	delay := 0
 	curInstruction := ""
-	instructions := ds.Stack[string](util.ReadProblemInput(files))
+	instructions := ds.Queue[string](util.ReadProblemInput(files))
 
 	for len(instructions) != 0 {
 		cycle++
 		doCycleProcessing(cycle, x)
 
 		if curInstruction == "" {
-			curInstruction, _ = instructions.Pop()
+			curInstruction, _ = instructions.Dequeue()
 			delay = GetDelay(curInstruction)
 		}
 
@@ -3,10 +3,13 @@ package main
 import (
 	"embed"
 	"fmt"
+	"math/big"
 	"strings"
 
 	ds "github.com/ShajeshJ/adventofcode_2022/common/datastructures"
 	"github.com/ShajeshJ/adventofcode_2022/common/logging"
+	"github.com/ShajeshJ/adventofcode_2022/common/num"
+	"github.com/ShajeshJ/adventofcode_2022/common/parse"
 	"github.com/ShajeshJ/adventofcode_2022/common/util"
 )
 
@@ -15,103 +18,117 @@ var log = logging.GetLogger()
 //go:embed input.txt
 var files embed.FS
 
-type Monkey struct {
-	items            []int
+type Monkey[T any] struct {
+	items            []T
 	inspectOperands  []string
 	inspectOperation string
-	divisibleNum     int
+	divisibleNum     T
 	trueTarget       int
 	falseTarget      int
 	inspectionCount  int
+	arith            num.Arith[T]
 }
 
-func (m *Monkey) InspectItem() {
-	operands := [2]int{}
+func (m *Monkey[T]) InspectItem() {
+	var operands [2]T
 	for i, operandStr := range m.inspectOperands {
 		if operandStr == "old" {
 			operands[i] = m.items[0]
 		} else {
-			operands[i] = util.AtoiNoError(operandStr)
+			operands[i] = num.Must(m.arith.Parse(operandStr))
 		}
 	}
 
 	if m.inspectOperation == "+" {
-		m.items[0] = operands[0] + operands[1]
+		m.items[0] = num.Must(m.arith.Add(operands[0], operands[1]))
 	} else {
 		// Assume multiply is the only other allowed
-		m.items[0] = operands[0] * operands[1]
+		m.items[0] = num.Must(m.arith.Mul(operands[0], operands[1]))
 	}
 
 	m.inspectionCount++
 }
 
-func (m *Monkey) CalcBoredom() {
-	m.items[0] = m.items[0] / 3
+func (m *Monkey[T]) CalcBoredom() {
+	m.items[0] = num.Must(m.arith.FloorDiv(m.items[0], m.arith.FromInt(3)))
 }
 
-func (m *Monkey) GetNextMonkey() int {
-	if m.items[0]%m.divisibleNum == 0 {
+func (m *Monkey[T]) GetNextMonkey() int {
+	if rem := num.Must(m.arith.Mod(m.items[0], m.divisibleNum)); m.arith.Cmp(rem, m.arith.FromInt(0)) == 0 {
 		return m.trueTarget
 	} else {
 		return m.falseTarget
 	}
 }
 
-func (m *Monkey) Pop() int {
+func (m *Monkey[T]) Pop() T {
 	val := m.items[0]
 	m.items = m.items[1:]
 	return val
 }
 
-func (m *Monkey) Push(item int) {
+func (m *Monkey[T]) Push(item T) {
 	m.items = append(m.items, item)
 }
 
-func getPartOneData() []Monkey {
-	monkeys := []Monkey{}
-	var curMonkey Monkey
-
-	for _, line := range util.ReadProblemInput(files) {
-		tokens := strings.Split(strings.TrimSpace(line), " ")
-
-		if tokens[0] == "Monkey" {
-			// Monkey {index}:
-			curMonkey = Monkey{}
-			continue
-		} else if tokens[0] == "Starting" {
-			// Start items: {items...}
-			for _, item := range tokens[2:] {
-				curMonkey.items = append(
-					curMonkey.items,
-					util.AtoiNoError(strings.Trim(item, ",")),
-				)
-			}
-		} else if tokens[0] == "Operation:" {
-			// Operation: new = {operand} {operator} {operand}
-			curMonkey.inspectOperands = []string{tokens[3], tokens[5]}
-			curMonkey.inspectOperation = tokens[4]
-		} else if tokens[0] == "Test:" {
-			// Test: divisible by {divisbleNum}
-			curMonkey.divisibleNum = util.AtoiNoError(tokens[3])
-		} else if tokens[0] == "If" && tokens[1] == "true:" {
-			// If true: throw to monkey {trueTarget}
-			curMonkey.trueTarget = util.AtoiNoError(tokens[5])
-		} else if tokens[0] == "If" && tokens[1] == "false:" {
-			// If false: throw to monkey {falseTarget}
-			curMonkey.falseTarget = util.AtoiNoError(tokens[5])
-		} else if tokens[0] == "" {
-			// Space between monkeys
-			monkeys = append(monkeys, curMonkey)
-		} else {
-			panic(fmt.Sprintf("Unexpected string %v", tokens))
+// getMonkeyBusiness returns the product of the inspection counts of the two most active monkeys
+func getMonkeyBusiness[T any](monkeys []Monkey[T]) int {
+	topTwoActive := ds.NewTopK(2, func(a, b Monkey[T]) bool {
+		return a.inspectionCount < b.inspectionCount
+	})
+	for _, m := range monkeys {
+		topTwoActive.Push(m)
+	}
+
+	top := topTwoActive.Sorted()
+	return top[0].inspectionCount * top[1].inspectionCount
+}
+
+// MonkeyNotes are the notes taken about a single monkey in the puzzle input
+type MonkeyNotes struct {
+	Items        []int  `parse:"items"`
+	LeftOperand  string `parse:"lhs"`
+	Operation    string `parse:"op"`
+	RightOperand string `parse:"rhs"`
+	DivisibleNum int    `parse:"divisible"`
+	TrueTarget   int    `parse:"true"`
+	FalseTarget  int    `parse:"false"`
+}
+
+var notesPattern = parse.MustCompile(strings.Join([]string{
+	"Monkey {_}:",
+	"Starting items: {items}",
+	"Operation: new = {lhs} {op} {rhs}",
+	"Test: divisible by {divisible}",
+	"If true: throw to monkey {true}",
+	"If false: throw to monkey {false}",
+}, "\n"))
+
+func getPartOneData[T any](a num.Arith[T]) []Monkey[T] {
+	monkeys := []Monkey[T]{}
+
+	for _, block := range parse.Blocks(util.ReadProblemInput(files)) {
+		var notes MonkeyNotes
+		lines := util.Map(block, strings.TrimSpace)
+		if err := notesPattern.Match(strings.Join(lines, "\n"), &notes); err != nil {
+			panic(err)
 		}
+
+		monkeys = append(monkeys, Monkey[T]{
+			items:            util.Map(notes.Items, a.FromInt),
+			inspectOperands:  []string{notes.LeftOperand, notes.RightOperand},
+			inspectOperation: notes.Operation,
+			divisibleNum:     a.FromInt(notes.DivisibleNum),
+			trueTarget:       notes.TrueTarget,
+			falseTarget:      notes.FalseTarget,
+			arith:            a,
+		})
 	}
-	monkeys = append(monkeys, curMonkey) // DOn't forget last monkey
 	return monkeys
 }
 
-func PartOne() any {
-	monkeys := getPartOneData()
+func partOne[T any](a num.Arith[T]) int {
+	monkeys := getPartOneData(a)
 
 	for i := 0; i < 20; i++ {
 		for m := 0; m < len(monkeys); m++ {
@@ -124,43 +141,46 @@ func PartOne() any {
 		}
 	}
 
-	topTwoActive := *ds.NewTopList[int](2)
-	for _, m := range monkeys {
-		topTwoActive.TryPush(m.inspectionCount)
-	}
+	return getMonkeyBusiness(monkeys)
+}
 
-	return topTwoActive.Values[0] * topTwoActive.Values[1]
+func PartOne() any {
+	if num.UseBig() {
+		return partOne[*big.Int](num.Big{})
+	}
+	return partOne[int](num.Int{})
 }
 
-func PartTwo() any {
-	monkeys := getPartOneData()
+func partTwo[T any](a num.Arith[T]) int {
+	monkeys := getPartOneData(a)
 
-	// We need to reduce using a modulo to avoid integer overflow
+	// We need to reduce using a modulo to keep worry levels from growing
+	// without bound (even the big backend can't hold 10000 rounds of squaring)
 	// But reducing item worry by a particular modulo will affect
 	// a monkey's divisible check if the modulo does not contain
 	// the monkey's divisibleNum as a factor; so we make the
-	// the modulo equal to a product of all monkey's divisibleNum
-	modReducer := 1
-	for _, m := range monkeys {
-		modReducer *= m.divisibleNum
-	}
+	// the modulo a common multiple of all monkey's divisibleNum
+	divisibleNums := util.Map(monkeys, func(m Monkey[T]) T { return m.divisibleNum })
+	modReducer := num.Must(num.LCM(a, divisibleNums...))
 
 	for i := 0; i < 10000; i++ {
 		for m := 0; m < len(monkeys); m++ {
 			for len(monkeys[m].items) > 0 {
 				monkeys[m].InspectItem()
 				nextM := monkeys[m].GetNextMonkey()
-				monkeys[nextM].Push(monkeys[m].Pop() % modReducer)
+				monkeys[nextM].Push(num.Must(a.Mod(monkeys[m].Pop(), modReducer)))
 			}
 		}
 	}
 
-	topTwoActive := *ds.NewTopList[int](2)
-	for _, m := range monkeys {
-		topTwoActive.TryPush(m.inspectionCount)
-	}
+	return getMonkeyBusiness(monkeys)
+}
 
-	return topTwoActive.Values[0] * topTwoActive.Values[1]
+func PartTwo() any {
+	if num.UseBig() {
+		return partTwo[*big.Int](num.Big{})
+	}
+	return partTwo[int](num.Int{})
 }
 
 func main() {
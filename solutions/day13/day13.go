@@ -59,30 +59,10 @@ func (p *PacketParser) ParseList() []any {
 }
 
 func getPartOneData() [][2]Packet {
-	var receivedPairs [][2]Packet
-	var curPair [2]Packet
-	fillIndex := 0
-
-	inputLines := util.ReadProblemInput(files)
-	if inputLines[len(inputLines)-1] != "" {
-		// Simplify for-loop parsing
-		inputLines = append(inputLines, "")
-	}
-
-	for _, line := range inputLines {
-		if line == "" {
-			receivedPairs = append(receivedPairs, curPair)
-			curPair = [2]Packet{}
-			fillIndex = 0
-			continue
-		}
-
-		p := PacketParser{[]rune(line)}
-		curPair[fillIndex] = p.ParseList()
-		fillIndex++
-	}
-
-	return receivedPairs
+	return util.Map(
+		util.Chunk(getPartTwoData(), 2),
+		func(pair []Packet) [2]Packet { return [2]Packet{pair[0], pair[1]} },
+	)
 }
 
 type CompareResult int
@@ -152,18 +132,11 @@ func PartOne() any {
 }
 
 func getPartTwoData() []Packet {
-	var receivedPairs []Packet
-
-	for _, line := range util.ReadProblemInput(files) {
-		if line == "" {
-			continue
-		}
-
+	lines := util.Filter(util.ReadProblemInput(files), func(line string) bool { return line != "" })
+	return util.Map(lines, func(line string) Packet {
 		p := PacketParser{[]rune(line)}
-		receivedPairs = append(receivedPairs, p.ParseList())
-	}
-
-	return receivedPairs
+		return p.ParseList()
+	})
 }
 
 func FindPacketIndex(packet Packet, others []Packet) int {
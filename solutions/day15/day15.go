@@ -3,9 +3,10 @@ package main
 import (
 	"embed"
 	"fmt"
-	"regexp"
 
+	"github.com/ShajeshJ/adventofcode_2022/common/interval"
 	"github.com/ShajeshJ/adventofcode_2022/common/logging"
+	"github.com/ShajeshJ/adventofcode_2022/common/parse"
 	"github.com/ShajeshJ/adventofcode_2022/common/util"
 	"golang.org/x/exp/slices"
 )
@@ -26,16 +27,16 @@ func ManhattanDist(p1, p2 []int) int {
 }
 
 func getPartOneData() []Sensor {
-	xregex := regexp.MustCompile(`x=(-?\d+)`)
-	yregex := regexp.MustCompile(`y=(-?\d+)`)
-
 	var sensors []Sensor
 	for _, line := range util.ReadProblemInput(files) {
-		xpoints := xregex.FindAllStringSubmatch(line, 2)
-		ypoints := yregex.FindAllStringSubmatch(line, 2)
+		// Sensor at x={0}, y={1}: closest beacon is at x={2}, y={3}
+		coords, err := parse.Ints(line)
+		if err != nil {
+			panic(err)
+		}
 		s := Sensor{
-			Point:  []int{util.AtoiNoError(xpoints[0][1]), util.AtoiNoError(ypoints[0][1])},
-			Beacon: []int{util.AtoiNoError(xpoints[1][1]), util.AtoiNoError(ypoints[1][1])},
+			Point:  coords[0:2],
+			Beacon: coords[2:4],
 		}
 		s.NoBeaconRange = ManhattanDist(s.Point, s.Beacon)
 		sensors = append(sensors, s)
@@ -43,90 +44,55 @@ func getPartOneData() []Sensor {
 	return sensors
 }
 
-type Range struct {
-	Min int
-	Max int
-}
-
-func CombineOrderedRanges(r Range, ranges []Range) []Range {
-	combined := []Range{}
-
-	breakIndex := -1
-	for i, r2 := range ranges {
-		if r2.Max+1 < r.Min {
-			combined = append(combined, r2)
+// GetCoverageAtY adds the x-ranges covered by each sensor at row `y` into `coverage`
+func GetCoverageAtY(y int, sensors []Sensor, coverage *interval.IntervalSet) {
+	for _, s := range sensors {
+		// sub the Y distance, and focus only on X
+		beaconlessRange := s.NoBeaconRange - util.Abs(s.Point[1]-y)
+		if beaconlessRange < 0 {
 			continue
 		}
-		if r.Max+1 < r2.Min {
-			breakIndex = i
-			break
-		}
-		r.Min = util.Min(r.Min, r2.Min)
-		r.Max = util.Max(r.Max, r2.Max)
-	}
-	combined = append(combined, r)
-	if breakIndex != -1 {
-		combined = append(combined, ranges[breakIndex:]...)
+		coverage.Insert(interval.Interval{
+			Min: s.Point[0] - beaconlessRange,
+			Max: s.Point[0] + beaconlessRange,
+		})
 	}
-	return combined
 }
 
 func PartOne() any {
 	sensors := getPartOneData()
 	targetY := 2_000_000
-	atTargetY := []Range{}
 	beaconsAtTargetY := []int{}
 
-	noBeaconCount := 0
+	atTargetY := &interval.IntervalSet{}
+	GetCoverageAtY(targetY, sensors, atTargetY)
+
+	noBeaconCount := atTargetY.TotalLen()
 	for _, s := range sensors {
 		if s.Beacon[1] == targetY && !slices.Contains(beaconsAtTargetY, s.Beacon[0]) {
 			noBeaconCount--
 			beaconsAtTargetY = append(beaconsAtTargetY, s.Beacon[0])
 		}
-
-		// sub the Y distance, and focus only on X
-		beaconlessRange := s.NoBeaconRange - util.Abs(s.Point[1]-targetY)
-		if beaconlessRange < 0 {
-			continue
-		}
-
-		atTargetY = CombineOrderedRanges(Range{s.Point[0] - beaconlessRange, s.Point[0] + beaconlessRange}, atTargetY)
-	}
-
-	for _, r := range atTargetY {
-		noBeaconCount += r.Max - r.Min + 1
 	}
 	return noBeaconCount
 }
 
-// InSensorRange returns true if the point is in range of a sensor, and returns the next
-// y-coordinate that's outside of that sensor's range; otherwise returns false and `y`
-func InSensorRange(x, y int, sensors []Sensor) (bool, int) {
-	for _, s := range sensors {
-		if ManhattanDist(s.Point, []int{x, y}) <= s.NoBeaconRange {
-			// nextY = {no-beacon range around the sensor} - (x dist from sensor) + 1
-			return true, s.Point[1] + (s.NoBeaconRange - util.Abs(s.Point[0]-x)) + 1
-		}
-	}
-	return false, y
-}
-
 func PartTwo() any {
 	sensors := getPartOneData()
 	maxCoords := 4_000_000
 
+	bounds := interval.Interval{Min: 0, Max: maxCoords}
+	coverage := &interval.IntervalSet{}
+
+	// The distress beacon is the only spot not covered by a sensor, so
+	// it'll be the first gap we find in any row's coverage
 	distressX, distressY := -1, -1
-	for x := 0; x <= maxCoords; x++ {
-		y := 0
-		for y <= maxCoords {
-			var inRange bool
-			if inRange, y = InSensorRange(x, y, sensors); !inRange {
-				distressX, distressY = x, y
-				break
-			}
-		}
+	for y := 0; y <= maxCoords; y++ {
+		coverage.Clear()
+		GetCoverageAtY(y, sensors, coverage)
 
-		if distressX != -1 {
+		if gaps := coverage.Gaps(bounds); len(gaps) > 0 {
+			distressX, distressY = gaps[0].Min, y
 			break
 		}
 	}
@@ -4,10 +4,15 @@ import (
 	"embed"
 	"fmt"
 	"math"
+	"os"
+	"sort"
 	"strings"
 
 	"github.com/ShajeshJ/adventofcode_2022/common/logging"
+	"github.com/ShajeshJ/adventofcode_2022/common/memo"
+	"github.com/ShajeshJ/adventofcode_2022/common/parse"
 	"github.com/ShajeshJ/adventofcode_2022/common/util"
+	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
 )
 
@@ -17,38 +22,48 @@ var log = logging.GetLogger()
 var files embed.FS
 
 type Valve struct {
-	ID      string
-	Rate    float64
-	LeadsTo []string
+	ID      string   `parse:"id"`
+	Rate    float64  `parse:"rate"`
+	LeadsTo []string `parse:"leads"`
 }
 
-func getPartOneData() map[string]Valve {
-	lines := util.ReadProblemInput(files)
-	valves := map[string]Valve{}
+// Valves with a single tunnel use the singular "tunnel leads to valve"
+var valvePattern = parse.MustCompile("Valve {id} has flow rate={rate}; [tunnels lead|tunnel leads] to valve[s] {leads}")
 
-	// [0]  [1] [2]  [3]   [4]     [5]   [6] [7]  [8]  [9]
-	// Valve XX has flow rate=X; tunnels lead to valves XX, XX, ...
-	for _, line := range lines {
-		tokens := strings.Split(line, " ")
-		id := tokens[1]
-		rate := float64(util.AtoiNoError(strings.Split(strings.Trim(tokens[4], ";"), "=")[1]))
-		leadTo := []string{}
-		for _, t := range tokens[9:] {
-			leadTo = append(leadTo, strings.Trim(t, ","))
-		}
-		valves[id] = Valve{id, rate, leadTo}
+func getPartOneData() map[string]Valve {
+	parsed, err := parse.MatchLines[Valve](valvePattern, util.ReadProblemInput(files))
+	if err != nil {
+		panic(err)
 	}
 
+	valves := map[string]Valve{}
+	for _, v := range parsed {
+		valves[v.ID] = v
+	}
 	return valves
 }
 
 type GraphConnectivity struct {
 	Graph  map[string]map[string]float64
 	Valves map[string]Valve
+	Index  map[string]int // Bit position of each valve, for encoding sets of valves
+	IDs    []string       // Valve at each bit position; the inverse of `Index`
+}
+
+// IndexValves assigns each of the graph's valves a bit position, so that sets
+// of valves can be stored as bitmasks
+func (g *GraphConnectivity) IndexValves() {
+	g.IDs = maps.Keys(g.Valves)
+	sort.Strings(g.IDs)
+
+	g.Index = map[string]int{}
+	for i, id := range g.IDs {
+		g.Index[id] = i
+	}
 }
 
 func InitShortestDistGraph(valves map[string]Valve) GraphConnectivity {
-	g := GraphConnectivity{map[string]map[string]float64{}, valves}
+	g := GraphConnectivity{Graph: map[string]map[string]float64{}, Valves: valves}
 
 	for id, valve := range valves {
 		g.Graph[id] = map[string]float64{id: 0}
@@ -84,224 +99,376 @@ func GetAllShortestDist(valves map[string]Valve) GraphConnectivity {
 	return g
 }
 
-func GetReleasedAmount(duration float64, opened []string, g GraphConnectivity) float64 {
-	var released float64 = 0
-	for _, id := range opened {
-		released += g.Valves[id].Rate * duration
-	}
-	return released
+// Opening is an agent arriving at `Valve` at the end of minute `Arrived`, and
+// opening it during `Minute`, so that it releases pressure from the next minute
+// onwards. `Released` is what the valve releases before time runs out, and
+// `Cumulative` is the total for the agent's route up to and including this valve
+type Opening struct {
+	Valve      string
+	Arrived    float64
+	Minute     float64
+	Released   float64
+	Cumulative float64
 }
 
-func GetHighestPressure(src, dest string, remaining float64, opened []string, g GraphConnectivity) float64 {
-	var released float64 = 0
+// Route is the order a single agent opens a set of valves in, and the total
+// pressure those valves release
+type Route struct {
+	Pressure float64
+	Openings []Opening
+}
 
-	if remaining <= g.Graph[src][dest] {
-		// Spend remaining time just running
-		return GetReleasedAmount(remaining, opened, g)
+// Plan is the best way for a group of agents to release pressure, where
+// `Schedules` has the route taken by each agent
+type Plan struct {
+	Start     string
+	Duration  float64
+	Pressure  float64
+	Schedules []Route
+}
+
+// maxDPValves limits the number of valves for the subset DP, which takes time
+// proportional to 3^valves per agent. Graphs with more valves use `SearchValves`
+const maxDPValves = 16
+
+// GetBestRoutes finds the best route for a single agent starting at `start` with
+// `duration` minutes, for every set of valves. Sets are indexed by their bitmask
+// over `g.Index`, and sets that can't all be opened in time have a pressure of -1
+func GetBestRoutes(g GraphConnectivity, start string, duration float64) []Route {
+	routes := make([]Route, 1<<len(g.IDs))
+	for i := range routes {
+		routes[i].Pressure = -1
 	}
 
-	// Run to dest valve, and open it
-	released += GetReleasedAmount(g.Graph[src][dest]+1, opened, g)
-	remaining -= g.Graph[src][dest] + 1
+	var openings []Opening
+	var visit func(pos string, elapsed float64, opened int, pressure float64)
+	visit = func(pos string, elapsed float64, opened int, pressure float64) {
+		if pressure > routes[opened].Pressure {
+			routes[opened] = Route{pressure, append([]Opening(nil), openings...)}
+		}
 
-	if remaining == 0 {
-		// Spent remaining time just opening the valve
-		return released
-	}
+		for i, dest := range g.IDs {
+			if opened&(1<<i) != 0 {
+				continue
+			}
 
-	// Add our destination as an opened valve
-	nextOpen := make([]string, len(opened))
-	copy(nextOpen, opened)
-	nextOpen = append(nextOpen, dest)
-
-	// Try remaining unopened valves, and use the highest released amount
-	var nextRelease float64 = 0
-	for nextDest := range g.Valves {
-		if !slices.Contains(nextOpen, nextDest) {
-			destRelease := GetHighestPressure(dest, nextDest, remaining, nextOpen, g)
-			if destRelease > nextRelease {
-				nextRelease = destRelease
+			// Run to dest valve, and open it
+			openedAt := elapsed + g.Graph[pos][dest] + 1
+			if openedAt >= duration {
+				// Opening it wouldn't release anything before time runs out
+				continue
 			}
-		}
-	}
 
-	// No more valves to open; just sit and wait for more pressure
-	if nextRelease == 0 {
-		nextRelease = GetReleasedAmount(remaining, nextOpen, g)
+			released := g.Valves[dest].Rate * (duration - openedAt)
+			openings = append(openings, Opening{
+				Valve:      dest,
+				Arrived:    openedAt - 1,
+				Minute:     openedAt,
+				Released:   released,
+				Cumulative: pressure + released,
+			})
+			visit(dest, openedAt, opened|1<<i, pressure+released)
+			openings = openings[:len(openings)-1]
+		}
 	}
+	visit(start, 0, 0, 0)
 
-	return released + nextRelease
+	return routes
 }
 
-func PartOne() any {
-	valves := getPartOneData()
-	g := GetAllShortestDist(valves)
-
-	// We don't care to travel to valves with 0 release rate
-	for k, v := range g.Valves {
-		if v.Rate == 0 {
-			delete(g.Valves, k)
-		}
+// PlanValves finds the most pressure that `agents` working together can release,
+// where each starts at `start` with `duration` minutes. Agents never need to open
+// the same valve, so the best plan splits the valves into a disjoint set per agent
+func PlanValves(g GraphConnectivity, start string, duration float64, agents int) Plan {
+	if agents < 1 {
+		panic("need at least 1 agent")
+	}
+	if len(g.IDs) > maxDPValves {
+		return SearchValves(g, start, duration, agents)
 	}
 
-	var highestRelease float64 = 0
-	for id := range g.Valves {
-		test := GetHighestPressure("AA", id, 30, []string{}, g)
-		if test > highestRelease {
-			highestRelease = test
+	routes := GetBestRoutes(g, start, duration)
+	full := len(routes) - 1
+
+	// best[m] is the most pressure the agents so far can release using only the
+	// valves in `m`, and picks[k][m] is the set of valves agent k opens to get there
+	var best []float64
+	picks := make([][]int, agents)
+	for k := 0; k < agents; k++ {
+		next := make([]float64, len(routes))
+		picks[k] = make([]int, len(routes))
+		for m := 0; m <= full; m++ {
+			next[m] = -1
+
+			// Try giving agent k every subset of `m`, leaving the rest to the others
+			for sub := m; ; sub = (sub - 1) & m {
+				if routes[sub].Pressure >= 0 && (k == 0 || best[m^sub] >= 0) {
+					test := routes[sub].Pressure
+					if k > 0 {
+						test += best[m^sub]
+					}
+					if test > next[m] {
+						next[m] = test
+						picks[k][m] = sub
+					}
+				}
+				if sub == 0 {
+					break
+				}
+			}
 		}
+		best = next
 	}
 
-	return highestRelease
+	plan := Plan{Start: start, Duration: duration, Pressure: best[full], Schedules: make([]Route, agents)}
+	for k, m := agents-1, full; k >= 0; k-- {
+		sub := picks[k][m]
+		plan.Schedules[k] = routes[sub]
+		m ^= sub
+	}
+	return plan
 }
 
-type Runner struct {
-	Src  string
-	Dest string
-	Dur  float64
+// maxSearchValves limits the number of valves for `SearchValves`, as sets of
+// opened valves are stored in a uint64
+const maxSearchValves = 64
+
+// searchState is agent number `agent` at `pos` with `left` minutes remaining, where
+// the valves in `opened` have been opened by it or the agents before it
+type searchState struct {
+	agent  int
+	pos    string
+	left   float64
+	opened uint64
 }
 
-func GetRemainingValveIDs(opened []string, g GraphConnectivity) []string {
-	remaining := []string{}
-	for id := range g.Valves {
-		if !slices.Contains(opened, id) {
-			remaining = append(remaining, id)
-		}
-	}
-	return remaining
+// searchMove is a step from one search state to the next. `valve` is the index of
+// the valve that was opened, or -1 if the agent handed over to the next one
+type searchMove struct {
+	next     searchState
+	valve    int
+	released float64
 }
 
-func SimPressureWithHelp(h, e Runner, remaining float64, opened []string, g GraphConnectivity) float64 {
-	if remaining == 0 || len(opened) == len(g.Valves) {
-		// If no time remaining, return 0
-		// If everything's opened, just wait for remaining time
-		return GetReleasedAmount(remaining, opened, g)
+// SearchValves is `PlanValves` for graphs with too many valves for the subset DP.
+// Agents take turns, each opening valves until it hands over to the next agent
+// back at the start, and the best pressure is memoized for each state on the way
+func SearchValves(g GraphConnectivity, start string, duration float64, agents int) Plan {
+	if len(g.IDs) > maxSearchValves {
+		panic(fmt.Sprintf("too many valves to plan for: %d > %d", len(g.IDs), maxSearchValves))
 	}
 
-	released := GetReleasedAmount(1, opened, g)
-	remaining--
-	h.Dur--
-	e.Dur--
+	moves := func(s searchState) []searchMove {
+		var next []searchMove
+		if s.agent+1 < agents {
+			next = append(next, searchMove{searchState{s.agent + 1, start, duration, s.opened}, -1, 0})
+		}
+		for i, dest := range g.IDs {
+			if s.opened&(1<<i) != 0 {
+				continue
+			}
 
-	if (h.Dur > 0 || h.Dest == "") && (e.Dur > 0 || e.Dest == "") {
-		// At least one runner still going, and nobody opened a new valve
-		return released + SimPressureWithHelp(h, e, remaining, opened, g)
+			// Run to dest valve, and open it
+			left := s.left - g.Graph[s.pos][dest] - 1
+			if left <= 0 {
+				// Opening it wouldn't release anything before time runs out
+				continue
+			}
+			next = append(next, searchMove{searchState{s.agent, dest, left, s.opened | 1<<i}, i, g.Valves[dest].Rate * left})
+		}
+		return next
 	}
 
-	// At least 1 valve opening
+	cache := memo.NewCache[searchState, float64](0)
+	best := memo.Recursive(func(self func(searchState) float64, s searchState) float64 {
+		pressure := 0.0
+		for _, m := range moves(s) {
+			pressure = math.Max(pressure, m.released+self(m.next))
+		}
+		return pressure
+	}, memo.Store[searchState, float64](cache))
+
+	initial := searchState{0, start, duration, 0}
+	plan := Plan{Start: start, Duration: duration, Pressure: best(initial), Schedules: make([]Route, agents)}
+
+	// Retrace the plan by following the moves that keep the best pressure reachable
+	for s, remaining := initial, plan.Pressure; ; {
+		next := moves(s)
+		i := slices.IndexFunc(next, func(m searchMove) bool { return m.released+best(m.next) == remaining })
+		if i < 0 {
+			break
+		}
 
-	nextOpen := make([]string, len(opened))
-	copy(nextOpen, opened)
+		m := next[i]
+		if m.valve >= 0 {
+			route := &plan.Schedules[s.agent]
+			route.Pressure += m.released
+			route.Openings = append(route.Openings, Opening{
+				Valve:      g.IDs[m.valve],
+				Arrived:    duration - m.next.left - 1,
+				Minute:     duration - m.next.left,
+				Released:   m.released,
+				Cumulative: route.Pressure,
+			})
+		}
+		s, remaining = m.next, remaining-m.released
+	}
 
-	// Set new runners for ones that finished
-	hdone, edone := h.Dur <= 0, e.Dur <= 0
-	if hdone {
-		nextOpen = append(nextOpen, h.Dest)
-		h = Runner{Src: h.Dest}
+	if os.Getenv(TraceEnvVar) == "1" {
+		stats := cache.Stats()
+		log.Infow("Searched valves", "states", cache.Len(), "hits", stats.Hits, "misses", stats.Misses)
 	}
-	if edone {
-		nextOpen = append(nextOpen, e.Dest)
-		e = Runner{Src: e.Dest}
+	return plan
+}
+
+// getAgentName returns what agent number `agent` out of `agents` is called in the
+// narrative. The first agent is you, and the rest are elephants, as in the puzzle text
+func getAgentName(agent, agents int) string {
+	switch {
+	case agent == 0:
+		return "You"
+	case agents == 2:
+		return "The elephant"
+	default:
+		return fmt.Sprintf("Elephant %d", agent)
 	}
+}
 
-	var bestRelease float64 = 0
-	simNextRelease := func() { // Helper for toil
-		test := SimPressureWithHelp(h, e, remaining, nextOpen, g)
-		if test > bestRelease {
-			bestRelease = test
+// NextHop returns the valve right after `from` on a shortest path to `to`. Ties
+// are broken alphabetically, so the narrative is the same between runs
+func (g *GraphConnectivity) NextHop(from, to string) string {
+	neighbours := maps.Keys(g.Graph[from])
+	sort.Strings(neighbours)
+	for _, n := range neighbours {
+		if g.Graph[from][n] == 1 && g.Graph[n][to] == g.Graph[from][to]-1 {
+			return n
 		}
 	}
-	resetDur := func(r *Runner) {
-		r.Dur = g.Graph[r.Src][r.Dest] + 1
-	}
+	panic(fmt.Sprintf("no path from %v to %v", from, to))
+}
 
-	var remainingIDs []string
-	if hdone && !edone {
-		remainingIDs = GetRemainingValveIDs(append(nextOpen, e.Dest), g)
-	} else if edone && !hdone {
-		remainingIDs = GetRemainingValveIDs(append(nextOpen, h.Dest), g)
-	} else {
-		remainingIDs = GetRemainingValveIDs(nextOpen, g)
+// DescribeSchedules lists the valves each agent opens, one line per valve
+func DescribeSchedules(plan Plan) string {
+	var sb strings.Builder
+	for agent, route := range plan.Schedules {
+		fmt.Fprintf(&sb, "%v (released %v):\n", getAgentName(agent, len(plan.Schedules)), route.Pressure)
+		for _, o := range route.Openings {
+			fmt.Fprintf(
+				&sb, "  minute %v: moved to %v by minute %v, opened it releasing %v (total %v)\n",
+				o.Minute, o.Valve, o.Arrived, o.Released, o.Cumulative,
+			)
+		}
 	}
+	return sb.String()
+}
 
-	if len(remainingIDs) == 0 {
-		// Runners don't matter; just sim arbitrarily
-		simNextRelease()
-		return released + bestRelease
-	}
+// Narrate describes the plan minute by minute, the same way as the puzzle text
+func Narrate(g GraphConnectivity, plan Plan) string {
+	duration := int(plan.Duration)
+
+	// actions[agent][minute] is what the agent does during that minute, if anything
+	actions := make([][]string, len(plan.Schedules))
+	openedAt := map[string]int{}
+	for agent, route := range plan.Schedules {
+		name := getAgentName(agent, len(plan.Schedules))
+		move, open := "moves", "opens"
+		if agent == 0 {
+			// "You" takes the plural form of the verbs
+			move, open = "move", "open"
+		}
 
-	// Exactly one runner choosing a new dest
-	if hdone != edone {
-		for _, id := range remainingIDs {
-			if hdone {
-				h.Dest = id
-				resetDur(&h)
-			} else {
-				e.Dest = id
-				resetDur(&e)
+		actions[agent] = make([]string, duration+1)
+		pos, minute := plan.Start, 1
+		for _, o := range route.Openings {
+			for pos != o.Valve {
+				pos = g.NextHop(pos, o.Valve)
+				actions[agent][minute] = fmt.Sprintf("%v %v to valve %v.", name, move, pos)
+				minute++
 			}
-			simNextRelease()
+			actions[agent][minute] = fmt.Sprintf("%v %v valve %v.", name, open, o.Valve)
+			openedAt[o.Valve] = minute
+			minute++
 		}
-		return released + bestRelease
 	}
 
-	// Both can choose a new dest
-
-	if len(remainingIDs) == 1 {
-		// If only 1 left unopened, don't bother simulating elephant
-		h.Dest = remainingIDs[0]
-		resetDur(&h)
-		simNextRelease()
-		return released + bestRelease
-	}
+	var sb strings.Builder
+	for minute := 1; minute <= duration; minute++ {
+		fmt.Fprintf(&sb, "== Minute %d ==\n", minute)
 
-	for i := 0; i < len(remainingIDs); i++ {
-		h.Dest = remainingIDs[i]
-		resetDur(&h)
+		var open []string
+		var rate float64
+		for id, at := range openedAt {
+			if at < minute {
+				open = append(open, id)
+				rate += g.Valves[id].Rate
+			}
+		}
+		sort.Strings(open)
+
+		switch len(open) {
+		case 0:
+			sb.WriteString("No valves are open.\n")
+		case 1:
+			fmt.Fprintf(&sb, "Valve %v is open, releasing %v pressure.\n", open[0], rate)
+		case 2:
+			fmt.Fprintf(&sb, "Valves %v and %v are open, releasing %v pressure.\n", open[0], open[1], rate)
+		default:
+			fmt.Fprintf(
+				&sb, "Valves %v, and %v are open, releasing %v pressure.\n",
+				strings.Join(open[:len(open)-1], ", "), open[len(open)-1], rate,
+			)
+		}
 
-		for j := i + 1; j < len(remainingIDs); j++ {
-			e.Dest = remainingIDs[j]
-			resetDur(&e)
-			simNextRelease()
+		for agent := range actions {
+			if action := actions[agent][minute]; action != "" {
+				sb.WriteString(action + "\n")
+			}
 		}
+		sb.WriteString("\n")
 	}
+	return sb.String()
+}
 
-	return released + bestRelease
+// TraceEnvVar is the environment variable that turns on printing of the plan
+// and a minute by minute narrative of it when set to "1"
+const TraceEnvVar = "AOC_TRACE"
+
+func logPlan(g GraphConnectivity, plan Plan, part int) {
+	if os.Getenv(TraceEnvVar) != "1" {
+		return
+	}
+	log.Infow("Valve schedules\n"+DescribeSchedules(plan), "part", part)
+	log.Infow("Narrative\n"+Narrate(g, plan), "part", part)
 }
 
-func PartTwo() any {
+func getValveGraph() GraphConnectivity {
 	valves := getPartOneData()
 	g := GetAllShortestDist(valves)
 
 	// We don't care to travel to valves with 0 release rate
-	idlist := []string{}
 	for k, v := range g.Valves {
 		if v.Rate == 0 {
 			delete(g.Valves, k)
-		} else {
-			idlist = append(idlist, k)
 		}
 	}
+	g.IndexValves()
 
-	var highestRelease float64 = 0
-	h, e := Runner{Src: "AA"}, Runner{Src: "AA"}
-
-	for i := 0; i < len(idlist); i++ {
-		h.Dest = idlist[i]
-		h.Dur = g.Graph[h.Src][h.Dest] + 1
-
-		for j := i + 1; j < len(idlist); j++ {
-			e.Dest = idlist[j]
-			e.Dur = g.Graph[e.Src][e.Dest] + 1
+	return g
+}
 
-			test := SimPressureWithHelp(h, e, 26, []string{}, g)
-			if test > highestRelease {
-				highestRelease = test
-			}
-		}
-	}
+func PartOne() any {
+	g := getValveGraph()
+	plan := PlanValves(g, "AA", 30, 1)
+	logPlan(g, plan, 1)
+	return plan.Pressure
+}
 
-	return highestRelease
+func PartTwo() any {
+	// Teaching the elephant takes 4 minutes
+	g := getValveGraph()
+	plan := PlanValves(g, "AA", 26, 2)
+	logPlan(g, plan, 2)
+	return plan.Pressure
 }
 
 func main() {
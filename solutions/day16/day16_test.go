@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSearchValvesMatchesPlanValves(t *testing.T) {
+	tests := []struct {
+		duration float64
+		agents   int
+		want     float64
+	}{
+		{30, 1, 1460},
+		{26, 2, 2117},
+		{26, 3, 2880},
+	}
+
+	g := getValveGraph()
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%v agents", tt.agents), func(t *testing.T) {
+			if tt.agents > 2 && testing.Short() {
+				t.Skip("searching for 3 agents takes a while")
+			}
+
+			dp := PlanValves(g, "AA", tt.duration, tt.agents)
+			search := SearchValves(g, "AA", tt.duration, tt.agents)
+			if dp.Pressure != tt.want || search.Pressure != tt.want {
+				t.Errorf("PlanValves = %v, SearchValves = %v, want %v", dp.Pressure, search.Pressure, tt.want)
+			}
+
+			// The retraced schedules must add up to the pressure that was found
+			total := 0.0
+			for _, route := range search.Schedules {
+				total += route.Pressure
+			}
+			if len(search.Schedules) != tt.agents || total != search.Pressure {
+				t.Errorf("SearchValves schedules for %d agents release %v, want %v", len(search.Schedules), total, search.Pressure)
+			}
+		})
+	}
+}
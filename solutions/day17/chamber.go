@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	ds "github.com/ShajeshJ/adventofcode_2022/common/datastructures"
+)
+
+// MaxChamberWidth is the widest the chamber can be, as each row is stored in a uint16
+const MaxChamberWidth = 16
+
+// PruneThreshold is how many rows the chamber can hold before it tries to
+// discard the ones that rocks can no longer reach
+const PruneThreshold = 256
+
+// Chamber is the falling rock chamber, where bit x of a row is set if column x
+// has rock in it. Only rows from `Floor` upwards are kept; the ones below have
+// been pruned since rocks can't reach them anymore
+type Chamber struct {
+	Rows  []uint16 // Rows[0] is the row at height `Floor`
+	Floor int
+	Width int
+}
+
+func NewChamber(width int) *Chamber {
+	if width < 1 || width > MaxChamberWidth {
+		panic(fmt.Sprintf("chamber width must be between 1 and %d, got %d", MaxChamberWidth, width))
+	}
+	return &Chamber{Width: width}
+}
+
+// Height returns the height of the highest rock in the chamber
+func (c *Chamber) Height() int {
+	return c.Floor + len(c.Rows)
+}
+
+// Row returns the mask of the row at height `y`. Pruned rows count as solid rock
+func (c *Chamber) Row(y int) uint16 {
+	switch {
+	case y < c.Floor:
+		return 1<<c.Width - 1
+	case y >= c.Height():
+		return 0
+	default:
+		return c.Rows[y-c.Floor]
+	}
+}
+
+// WillCollide returns whether `shape` would hit a wall, the floor or another rock,
+// with its bottom left corner at `x`, `y`
+func (c *Chamber) WillCollide(shape Shape, x, y int) bool {
+	if x < 0 || y < 0 || x+shape.Width > c.Width {
+		return true
+	}
+
+	for dy, mask := range shape.Rows {
+		if c.Row(y+dy)&(mask<<x) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Place fills in the spaces taken by `shape`, with its bottom left corner at `x`, `y`
+func (c *Chamber) Place(shape Shape, x, y int) {
+	for dy, mask := range shape.Rows {
+		for y+dy >= c.Height() {
+			c.Rows = append(c.Rows, 0)
+		}
+		c.Rows[y+dy-c.Floor] |= mask << x
+	}
+}
+
+// Reachable finds the empty spaces that a falling rock could get to, by moving
+// left, right and down from above the highest rock. The spaces are returned as
+// row masks from the top down, starting with the row just above the highest
+// rock, along with the height of the lowest row that was reached
+func (c *Chamber) Reachable() ([]uint16, int) {
+	height := c.Height()
+	reached := []uint16{1<<c.Width - 1} // The row above the highest rock is entirely open
+
+	toProcess := ds.Stack[[2]int]{}
+	for x := 0; x < c.Width; x++ {
+		toProcess.Push([2]int{x, height})
+	}
+
+	for !toProcess.IsEmpty() {
+		p, _ := toProcess.Pop()
+		for _, next := range [][2]int{{p[0] - 1, p[1]}, {p[0] + 1, p[1]}, {p[0], p[1] - 1}} {
+			x, y := next[0], next[1]
+			if x < 0 || x >= c.Width || y < c.Floor || c.Row(y)&(1<<x) != 0 {
+				continue
+			}
+
+			depth := height - y
+			for depth >= len(reached) {
+				reached = append(reached, 0)
+			}
+			if reached[depth]&(1<<x) != 0 {
+				continue
+			}
+			reached[depth] |= 1 << x
+			toProcess.Push(next)
+		}
+	}
+
+	return reached, height - len(reached) + 1
+}
+
+// Prune discards the rows below the lowest space a falling rock could reach
+func (c *Chamber) Prune() {
+	_, lowest := c.Reachable()
+	if lowest <= c.Floor {
+		return
+	}
+
+	// Copy the rows that are kept, so the old ones can be garbage collected
+	c.Rows = append([]uint16(nil), c.Rows[lowest-c.Floor:]...)
+	c.Floor = lowest
+}
+
+// Profile returns a fingerprint of the chamber's surface, relative to its height.
+// Since rocks can only ever touch the reachable spaces, two chambers with the same
+// profile will play out the same way from then on
+func (c *Chamber) Profile() string {
+	reached, _ := c.Reachable()
+
+	var sb strings.Builder
+	for _, mask := range reached {
+		sb.WriteByte(byte(mask))
+		sb.WriteByte(byte(mask >> 8))
+	}
+	return sb.String()
+}
+
+func (c *Chamber) String() string {
+	var sb strings.Builder
+	for y := c.Height() - 1; y >= c.Floor; y-- {
+		sb.WriteByte('|')
+		for x := 0; x < c.Width; x++ {
+			if c.Row(y)&(1<<x) != 0 {
+				sb.WriteRune(RockRune)
+			} else {
+				sb.WriteRune(EmptySpaceRune)
+			}
+		}
+		fmt.Fprintf(&sb, "| %d\n", y)
+	}
+
+	if c.Floor == 0 {
+		sb.WriteString("+" + strings.Repeat("-", c.Width) + "+\n")
+	} else {
+		sb.WriteString("~" + strings.Repeat("~", c.Width) + "~\n")
+	}
+	return sb.String()
+}
@@ -3,15 +3,16 @@ package main
 import (
 	"embed"
 	"fmt"
+	"strings"
 
+	"github.com/ShajeshJ/adventofcode_2022/common/cycle"
 	"github.com/ShajeshJ/adventofcode_2022/common/logging"
 	"github.com/ShajeshJ/adventofcode_2022/common/util"
-	"golang.org/x/exp/slices"
 )
 
 var log = logging.GetLogger()
 
-//go:embed input.txt
+//go:embed input.txt rocks.txt
 var files embed.FS
 
 type Direction int
@@ -22,8 +23,26 @@ const (
 	Right
 )
 
-const ChamberWidth = 7
-const ChamberVerticalBuffer = 7
+// Config describes the chamber and the rocks that fall into it
+type Config struct {
+	Width  int // Width of the chamber
+	SpawnX int // Gap between the left wall and a new rock
+	SpawnY int // Gap between the highest rock (or the floor) and a new rock
+	Shapes []Shape
+}
+
+// DefaultConfig is the chamber from the puzzle, with the rocks from rocks.txt
+func DefaultConfig() Config {
+	bytes, err := files.ReadFile("rocks.txt")
+	if err != nil {
+		panic(err)
+	}
+	shapes, err := ParseShapes(strings.Split(string(bytes), "\n"))
+	if err != nil {
+		panic(err)
+	}
+	return Config{Width: 7, SpawnX: 2, SpawnY: 3, Shapes: shapes}
+}
 
 const (
 	RockRune       = '#'
@@ -43,198 +62,101 @@ func GetWindGenerator(dirs string) func(i int) (Direction, int) {
 	}
 }
 
-// CreateChamber returns a 2D rune array representing the falling rock chamber
-func CreateChamber() *[][]rune {
-	// Start 7 tall to accomodate tallest vertical rock
-	chamber := make([][]rune, ChamberVerticalBuffer)
-
-	for y := 0; y < len(chamber); y++ {
-		chamber[y] = make([]rune, ChamberWidth)
-		for x := 0; x < len(chamber[y]); x++ {
-			chamber[y][x] = EmptySpaceRune
-		}
-	}
-
-	return &chamber
+type RockWindIndexes struct {
+	Rock, Wind int
 }
 
-// ExpandChamber expands the vertical height of the chamber up to the minimum necessary buffer
-func ExpandChamber(height int, chamber *[][]rune) {
-	// appendAmt = minBuffer - numEmptyRowsFromTop
-	// numEmptyRowsFromTop = chamberHeight - highestRockHeight
-	appendAmt := ChamberVerticalBuffer - (len(*chamber) - height)
+// SimulationKey identifies a simulation state for loop detection. `Surface` is
+// the chamber's `Profile`, so two states with the same key play out the same way
+type SimulationKey struct {
+	RockWindIndexes
+	Surface string
+}
 
-	for i := 0; i < appendAmt; i++ {
-		row := make([]rune, ChamberWidth)
-		for x := 0; x < len(row); x++ {
-			row[x] = EmptySpaceRune
-		}
-		*chamber = append(*chamber, row)
-	}
+// Simulation tracks the chamber, and which rock and wind comes next
+type Simulation struct {
+	Chamber *Chamber
+	RockWindIndexes
+	cfg     Config
+	getWind func(i int) (Direction, int)
+	getRock func(i int) (Rock, int)
 }
 
-// GetHeight returns the height of the highest rock in `chamber`
-func GetHeight(chamber [][]rune) int {
-	for i := len(chamber) - 1; i >= 0; i-- {
-		if slices.Contains(chamber[i], RockRune) {
-			return i + 1
+func NewSimulation(cfg Config) *Simulation {
+	for _, shape := range cfg.Shapes {
+		if shape.Width+cfg.SpawnX > cfg.Width {
+			panic(fmt.Sprintf("a rock %d wide can't spawn in a chamber %d wide", shape.Width, cfg.Width))
 		}
 	}
-	return 0
-}
 
-func PrintChamber(chamber [][]rune) {
-	for i := len(chamber) - 1; i >= 0; i-- {
-		log.Info("|", string(chamber[i]), "|", i)
+	input := util.ReadProblemInput(files)[0]
+	return &Simulation{
+		Chamber: NewChamber(cfg.Width),
+		cfg:     cfg,
+		getWind: GetWindGenerator(input),
+		getRock: GetRockGenerator(cfg.Shapes),
 	}
-	log.Info("+-------+")
 }
 
-type RockWindIndexes struct {
-	Rock, Wind int
-}
+// DropRock drops the next rock into the chamber, and lets it fall until it comes to rest
+func (s *Simulation) DropRock() {
+	height := s.Height()
 
-// RunRockSimulation will run the run simulation from the `start` indexes,
-// and will attempt to drop `numRocks` until the `end` indexes. If the `end` indexes
-// are -1, then the simulation will go until all `numRocks` are thrown.
-// The added height and number of rocks thrown will be returned
-func RunRockSimulation(start, end RockWindIndexes, numRocks int, chamber *[][]rune) (int, int) {
-	input := util.ReadProblemInput(files)[0]
-	getWind := GetWindGenerator(input)
-	getRock := GetRockGenerator()
-
-	rockLoopIdx, windLoopIdx := start.Rock, start.Wind
-	startHeight := GetHeight(*chamber)
-
-	for i := 1; i <= numRocks; i++ {
-		height := GetHeight(*chamber)
-		ExpandChamber(height, chamber)
-
-		var rock Rock
-		rock, rockLoopIdx = getRock(rockLoopIdx)
-		rock.InitPosition(2, height+3)
-
-		for {
-			var wind Direction
-			wind, windLoopIdx = getWind(windLoopIdx)
-			rock.Move(wind, *chamber)
-			stillFalling := rock.Move(Down, *chamber)
-			if !stillFalling {
-				rock.PlaceRock(chamber)
-				break
-			}
-		}
+	var rock Rock
+	rock, s.Rock = s.getRock(s.Rock)
+	rock.X, rock.Y = s.cfg.SpawnX, height+s.cfg.SpawnY
 
-		if end.Rock == -1 || end.Wind == -1 {
-			continue
-		}
-
-		if end.Rock == rockLoopIdx && end.Wind == windLoopIdx {
-			// PrintChamber(chamber)
-			return GetHeight(*chamber) - startHeight, i
+	for {
+		var wind Direction
+		wind, s.Wind = s.getWind(s.Wind)
+		rock.Move(wind, s.Chamber)
+		stillFalling := rock.Move(Down, s.Chamber)
+		if !stillFalling {
+			rock.PlaceRock(s.Chamber)
+			if len(s.Chamber.Rows) > PruneThreshold {
+				s.Chamber.Prune()
+			}
+			return
 		}
 	}
-
-	// PrintChamber(chamber)
-	return GetHeight(*chamber) - startHeight, numRocks
 }
 
-func PartOne() any {
-	height, _ := RunRockSimulation(
-		RockWindIndexes{0, 0},
-		RockWindIndexes{-1, -1},
-		2022,
-		CreateChamber(),
-	)
-	return height
+// Height returns the height of the highest rock in the chamber
+func (s *Simulation) Height() int {
+	return s.Chamber.Height()
 }
 
-// GetRepeatingIndexes simulates the rock fall from part 1, until
-// it finds a repeating index pattern
-func GetRepeatingIndexes() (rockIdx, windIdx int) {
-	input := util.ReadProblemInput(files)[0]
-	getWind := GetWindGenerator(input)
-	getRock := GetRockGenerator()
-	chamber := *CreateChamber()
-
-	foundPairs := []RockWindIndexes{}
-
-	for {
-		height := GetHeight(chamber)
-		ExpandChamber(height, &chamber)
-
-		var rock Rock
-		rock, rockIdx = getRock(rockIdx)
-		rock.InitPosition(2, height+3)
-
-		for {
-			var wind Direction
-			wind, windIdx = getWind(windIdx)
-			rock.Move(wind, chamber)
-			stillFalling := rock.Move(Down, chamber)
-			if !stillFalling {
-				rock.PlaceRock(&chamber)
-				break
-			}
-		}
+// Key returns the upcoming rock and wind indexes, along with the chamber's surface profile
+func (s *Simulation) Key() SimulationKey {
+	return SimulationKey{s.RockWindIndexes, s.Chamber.Profile()}
+}
 
-		curPair := RockWindIndexes{rockIdx, windIdx}
-		if slices.Contains(foundPairs, curPair) {
-			return
-		}
-		foundPairs = append(foundPairs, curPair)
+func PartOne() any {
+	sim := NewSimulation(DefaultConfig())
+	for i := 0; i < 2022; i++ {
+		sim.DropRock()
 	}
+	return sim.Height()
 }
 
 func PartTwo() any {
-	totalHeight, totalRocks := 0, 1_000_000_000_000
-	loopRockIdx, loopWindIdx := GetRepeatingIndexes()
-	chamber := CreateChamber()
-
-	// Simulate the first bit, just before the looped portion
-	startSimHeight, startSimThrown := RunRockSimulation(
-		RockWindIndexes{0, 0},
-		RockWindIndexes{loopRockIdx, loopWindIdx},
-		totalRocks,
-		chamber,
+	// Rocks and wind loop, so eventually so does the chamber's surface; find that
+	// loop and extrapolate the height, rather than simulating every rock
+	loop, err := cycle.Find(
+		NewSimulation(DefaultConfig()),
+		func(s *Simulation) *Simulation {
+			s.DropRock()
+			return s
+		},
+		(*Simulation).Key,
+		(*Simulation).Height,
+		0,
 	)
-	totalHeight += startSimHeight
-	totalRocks -= startSimThrown
-
-	// Simulate the first loop explicitly so the final chamber terrain matches the end of loop
-	l1SimHeight, l1SimThrown := RunRockSimulation(
-		RockWindIndexes{loopRockIdx, loopWindIdx},
-		RockWindIndexes{loopRockIdx, loopWindIdx},
-		totalRocks,
-		chamber,
-	)
-	totalHeight += l1SimHeight
-	totalRocks -= l1SimThrown
-
-	// Simulate loop again, but this time we can simply multiple the resulting outputs to
-	// quickly multiple/add the looped portion without literally simulating it
-	loopSimHeight, loopSimThrown := RunRockSimulation(
-		RockWindIndexes{loopRockIdx, loopWindIdx},
-		RockWindIndexes{loopRockIdx, loopWindIdx},
-		totalRocks,
-		chamber,
-	)
-	requiredIterations := totalRocks / loopSimThrown
-	totalHeight += loopSimHeight * requiredIterations
-	totalRocks -= loopSimThrown * requiredIterations
-
-	// Simulate the remaining rocks, after the final looped portion
-	endSimHeight, endSimThrown := RunRockSimulation(
-		RockWindIndexes{loopRockIdx, loopWindIdx},
-		RockWindIndexes{-1, -1},
-		totalRocks,
-		chamber,
-	)
-
-	totalHeight += endSimHeight
-	totalRocks -= endSimThrown
+	if err != nil {
+		panic(err)
+	}
 
-	return totalHeight
+	return loop.At(1_000_000_000_000)
 }
 
 func main() {
@@ -3,11 +3,12 @@ package main
 import (
 	"embed"
 	"fmt"
+	"os"
 	"strings"
 
+	ds "github.com/ShajeshJ/adventofcode_2022/common/datastructures"
 	"github.com/ShajeshJ/adventofcode_2022/common/logging"
 	"github.com/ShajeshJ/adventofcode_2022/common/util"
-	"golang.org/x/exp/maps"
 )
 
 var log = logging.GetLogger()
@@ -42,33 +43,36 @@ func GetAdjacent(v Voxel) []Voxel {
 	}
 }
 
-func GetNumAdjacentLava(v Voxel, lavaMap map[Voxel]bool) int {
+func GetNumAdjacentLava(v Voxel, lavaMap ds.Set[Voxel]) int {
 	count := 0
 	for _, adj := range GetAdjacent(v) {
-		if _, ok := lavaMap[adj]; ok {
+		if lavaMap.Has(adj) {
 			count++
 		}
 	}
 	return count
 }
 
-func PartOne() any {
-	input := getPartOneData()
-	lavaMap := map[Voxel]bool{}
-
+// GetSurfaceArea counts the sides of `voxels` that aren't touching another one of `voxels`
+func GetSurfaceArea(voxels []Voxel) int {
+	placed := ds.Set[Voxel]{}
 	totalSides := 0
 
-	for _, v := range input {
-		// We subtract 2 for each adjacent lava, to account for over-counting done
-		// by the adjacent lava previously
-		addedSides := 6 - 2*GetNumAdjacentLava(v, lavaMap)
+	for _, v := range voxels {
+		// We subtract 2 for each adjacent voxel, to account for over-counting done
+		// by the adjacent voxel previously
+		addedSides := 6 - 2*GetNumAdjacentLava(v, placed)
 		totalSides += addedSides
-		lavaMap[v] = true
+		placed.Add(v)
 	}
 
 	return totalSides
 }
 
+func PartOne() any {
+	return GetSurfaceArea(getPartOneData())
+}
+
 type BoundingBox struct {
 	MinX, MaxX, MinY, MaxY, MinZ, MaxZ int
 }
@@ -119,67 +123,53 @@ func GetBoundingBox(voxels []Voxel) BoundingBox {
 	return box
 }
 
-func GetOpenAirVoxels(lavaMap map[Voxel]bool, box BoundingBox) map[Voxel]bool {
-	// Bounding box edges are all air by construction
-	start := Voxel{box.MinX, box.MinY, box.MinZ}
-	openAirVoxels := map[Voxel]bool{start: true}
-	toProcess := map[Voxel]bool{start: true}
-
-	for len(toProcess) > 0 {
-		nextOpenAirV := maps.Keys(toProcess)[0]
-		delete(toProcess, nextOpenAirV)
-
-		for _, adjV := range GetAdjacent(nextOpenAirV) {
-			if _, ok := openAirVoxels[adjV]; ok {
-				continue
-			}
-			if _, ok := lavaMap[adjV]; ok {
-				continue
-			}
-			if box.IsOutside(adjV) {
-				continue
+// GetAirPockets returns the groups of air voxels in the box that are completely
+// enclosed by lava, and so can't be reached from outside
+func GetAirPockets(lavaMap ds.Set[Voxel], box BoundingBox) [][]Voxel {
+	var air []Voxel
+	for x := box.MinX; x <= box.MaxX; x++ {
+		for y := box.MinY; y <= box.MaxY; y++ {
+			for z := box.MinZ; z <= box.MaxZ; z++ {
+				if v := (Voxel{x, y, z}); !lavaMap.Has(v) {
+					air = append(air, v)
+				}
 			}
-			openAirVoxels[adjV] = true
-			toProcess[adjV] = true
 		}
 	}
 
-	return openAirVoxels
+	components := ds.ConnectedComponents(air, GetAdjacent)
+
+	// Bounding box edges are all air by construction, and connect to the outside
+	outside, _ := components.Label(Voxel{box.MinX, box.MinY, box.MinZ})
+
+	var pockets [][]Voxel
+	for i, members := range components.Members {
+		if i != outside {
+			pockets = append(pockets, members)
+		}
+	}
+	return pockets
 }
 
+// TraceEnvVar is the environment variable that turns on printing of the trapped
+// air pockets when set to "1"
+const TraceEnvVar = "AOC_TRACE"
+
 func PartTwo() any {
 	input := getPartOneData()
 	box := GetBoundingBox(input)
+	pockets := GetAirPockets(ds.NewSet(input...), box)
 
-	lavaMap := map[Voxel]bool{}
-	for _, v := range input {
-		lavaMap[v] = true
+	// The inside of each air pocket can't cool, which is the same as the pocket's
+	// own surface area since it's entirely bordered by lava
+	totalSides := GetSurfaceArea(input)
+	for _, pocket := range pockets {
+		totalSides -= GetSurfaceArea(pocket)
 	}
-	openAirMap := GetOpenAirVoxels(lavaMap, box)
-
-	// Start by assuming all lava voxel sides will cool
-	totalSides := 6 * len(lavaMap)
 
-	for x := box.MinX; x <= box.MaxX; x++ {
-		for y := box.MinY; y <= box.MaxY; y++ {
-			for z := box.MinZ; z <= box.MaxZ; z++ {
-				v := Voxel{x, y, z}
-				numAdjacentLava := GetNumAdjacentLava(v, lavaMap)
-				if _, ok := lavaMap[v]; ok {
-					// is a lava voxel; reduce overcounting by adjacent
-					// lava voxels' sides, same as part 1
-					totalSides -= numAdjacentLava
-					continue
-				}
-				if _, ok := openAirMap[v]; !ok {
-					// is a trapped air voxel; reduce overcounting by adjacent
-					// lava voxels' sides, as if the air was a lava voxel
-					totalSides -= numAdjacentLava
-				}
-			}
-		}
+	if os.Getenv(TraceEnvVar) == "1" {
+		log.Infow("Found trapped air", "pockets", len(pockets), "part", 2)
 	}
-
 	return totalSides
 }
 
@@ -1,14 +1,18 @@
 package main
 
 import (
+	"context"
 	"embed"
 	"fmt"
+	"os"
 	"regexp"
-	"sync"
+	"strings"
 
 	"github.com/ShajeshJ/adventofcode_2022/common/logging"
+	"github.com/ShajeshJ/adventofcode_2022/common/parse"
+	"github.com/ShajeshJ/adventofcode_2022/common/pool"
+	"github.com/ShajeshJ/adventofcode_2022/common/search"
 	"github.com/ShajeshJ/adventofcode_2022/common/util"
-	"golang.org/x/exp/slices"
 )
 
 var log = logging.GetLogger()
@@ -16,68 +20,137 @@ var log = logging.GetLogger()
 //go:embed input.txt
 var files embed.FS
 
-const (
-	None = iota
-	Ore
-	Clay
-	Obsidian
-	Geode
-)
+// MaxResources is the most resource types a scenario can have, so that items fit
+// in a fixed size array and factories can be compared cheaply
+const MaxResources = 8
 
+// ResourceType is the index of a resource in a scenario's `Resources`. Each type
+// of bot is named after the resource it collects
 type ResourceType int
 
-type Items struct {
-	Ore      int
-	Clay     int
-	Obsidian int
-	Geode    int
-}
-
-func (i *Items) Add(t ResourceType, amt int) {
-	switch t {
-	case Ore:
-		i.Ore += amt
-	case Clay:
-		i.Clay += amt
-	case Obsidian:
-		i.Obsidian += amt
-	case Geode:
-		i.Geode += amt
-	}
-}
+// Items holds an amount of each resource type
+type Items [MaxResources]int
 
 type Robot = Items
 
-func (i *Items) Contains(other Items) bool {
-	return i.Ore >= other.Ore &&
-		i.Clay >= other.Clay &&
-		i.Obsidian >= other.Obsidian
-	// don't bother checking geode
-}
-
 // Add adds the items in other to i
 func Add(i, other Items) Items {
-	return Items{
-		Ore:      i.Ore + other.Ore,
-		Clay:     i.Clay + other.Clay,
-		Obsidian: i.Obsidian + other.Obsidian,
-		Geode:    i.Geode + other.Geode,
+	for r := range i {
+		i[r] += other[r]
 	}
+	return i
 }
 
 // Subtract subtracts the items in other from i
 func Subtract(i, other Items) Items {
-	return Items{
-		Ore:      i.Ore - other.Ore,
-		Clay:     i.Clay - other.Clay,
-		Obsidian: i.Obsidian - other.Obsidian,
-		Geode:    i.Geode - other.Geode,
+	for r := range i {
+		i[r] -= other[r]
 	}
+	return i
+}
+
+// Scale multiplies every item by `n`
+func Scale(i Items, n int) Items {
+	for r := range i {
+		i[r] *= n
+	}
+	return i
 }
 
 type Blueprint struct {
 	ID    int
-	Costs map[ResourceType]Items
+	Costs map[ResourceType]Items // Bots that aren't in the map can't be built
+}
+
+// Scenario is the set of resources that blueprints are written in terms of,
+// along with what factories start with and what they're trying to collect
+type Scenario struct {
+	Resources []string
+	Objective ResourceType
+	StartBots Robot
+}
+
+// Resource returns the type of the resource called `name`, adding it if it's new
+func (s *Scenario) Resource(name string) (ResourceType, error) {
+	for i, r := range s.Resources {
+		if r == name {
+			return ResourceType(i), nil
+		}
+	}
+	if len(s.Resources) == MaxResources {
+		return 0, fmt.Errorf("can't have more than %d resource types, adding %q", MaxResources, name)
+	}
+	s.Resources = append(s.Resources, name)
+	return ResourceType(len(s.Resources) - 1), nil
+}
+
+func (s *Scenario) Name(t ResourceType) string {
+	return s.Resources[t]
+}
+
+// MaxSpend returns the most of each resource that can be spent in a single minute.
+// Since only one bot can be built per minute, there's no point having more bots
+// collecting a resource than this
+func (s *Scenario) MaxSpend(bp Blueprint) Items {
+	var spend Items
+	for _, cost := range bp.Costs {
+		for r := range spend {
+			spend[r] = util.Max(spend[r], cost[r])
+		}
+	}
+	return spend
+}
+
+var (
+	blueprintRegex = regexp.MustCompile(`^Blueprint (\d+):`)
+	recipeRegex    = regexp.MustCompile(`Each (\w+) robot costs ([^.]+)\.`)
+	costRegex      = regexp.MustCompile(`^(\d+) (\w+)$`)
+)
+
+// ParseBlueprints reads blueprints made up of recipes like "Each <resource> robot
+// costs <n> <resource> and <n> <resource>.", with any resource names. Resources are
+// added to `s` in the order they're first mentioned
+func ParseBlueprints(s *Scenario, lines []string) ([]Blueprint, error) {
+	var blueprints []Blueprint
+	for i, line := range lines {
+		if line == "" {
+			continue
+		}
+		wrap := func(err error) error { return &parse.Error{Line: i + 1, Err: err} }
+
+		id := blueprintRegex.FindStringSubmatch(line)
+		if id == nil {
+			return nil, wrap(fmt.Errorf("missing blueprint ID"))
+		}
+		bp := Blueprint{util.AtoiNoError(id[1]), map[ResourceType]Items{}}
+
+		for _, recipe := range recipeRegex.FindAllStringSubmatch(line, -1) {
+			bot, err := s.Resource(recipe[1])
+			if err != nil {
+				return nil, wrap(err)
+			}
+
+			var cost Items
+			for _, part := range strings.Split(recipe[2], " and ") {
+				match := costRegex.FindStringSubmatch(strings.TrimSpace(part))
+				if match == nil {
+					return nil, wrap(fmt.Errorf("invalid cost %q", part))
+				}
+				res, err := s.Resource(match[2])
+				if err != nil {
+					return nil, wrap(err)
+				}
+				cost[res] += util.AtoiNoError(match[1])
+			}
+			bp.Costs[bot] = cost
+		}
+
+		if len(bp.Costs) == 0 {
+			return nil, wrap(fmt.Errorf("blueprint %d has no recipes", bp.ID))
+		}
+		blueprints = append(blueprints, bp)
+	}
+	return blueprints, nil
 }
 
 // SumN is a function which finds the sum from 1 to n
@@ -85,127 +158,234 @@ func SumN(n int) int {
 	return (n * (n + 1)) / 2
 }
 
-func getPartOneData() []Blueprint {
-	// Regex Indicies:
-	// Blueprint {0}: Each ore robot costs {1} ore.
-	// Each clay robot costs {2} ore.
-	// Each obsidian robot costs {3} ore and {4} clay.
-	// Each geode robot costs {5} ore and {6} obsidian.
-	regex := regexp.MustCompile(`\d+`)
-	blueprints := []Blueprint{}
-	for _, line := range util.ReadProblemInput(files) {
-		vals := regex.FindAllStringSubmatch(line, -1)
-		bp := Blueprint{util.AtoiNoError(vals[0][0]), make(map[ResourceType]Items)}
-		bp.Costs[Ore] = Items{Ore: util.AtoiNoError(vals[1][0])}
-		bp.Costs[Clay] = Items{Ore: util.AtoiNoError(vals[2][0])}
-		bp.Costs[Obsidian] = Items{Ore: util.AtoiNoError(vals[3][0]), Clay: util.AtoiNoError(vals[4][0])}
-		bp.Costs[Geode] = Items{Ore: util.AtoiNoError(vals[5][0]), Obsidian: util.AtoiNoError(vals[6][0])}
-		blueprints = append(blueprints, bp)
+// getPartOneData returns the blueprints, along with the puzzle's scenario of
+// starting with a single ore bot, and trying to open geodes
+func getPartOneData() (Scenario, []Blueprint) {
+	var s Scenario
+	blueprints, err := ParseBlueprints(&s, util.ReadProblemInput(files))
+	if err != nil {
+		panic(err)
+	}
+
+	ore, _ := s.Resource("ore")
+	geode, err := s.Resource("geode")
+	if err != nil {
+		panic(err)
 	}
-	return blueprints
+	s.StartBots[ore] = 1
+	s.Objective = geode
+	return s, blueprints
 }
 
-type DecisionFactors struct {
-	bp          Blueprint
-	minute      int
-	items       Items
-	bots        Robot
-	nextBotType ResourceType
-	curMax      Items
-	exclude     []ResourceType
+// Build is a bot that was built, at the end of the given minute (counting up from 1)
+type Build struct {
+	Bot    ResourceType
+	Minute int
 }
 
-func Simulate(d DecisionFactors) Items {
-	d.exclude = []ResourceType{}
-	if d.nextBotType == None {
-		// If we had the ability to build a bot in this minute,
-		// but chose to do nothing, then it doesn't make sense to
-		// build those bots during the next minute; so add those to the exclude list
-		for bot, cost := range d.bp.Costs {
-			if d.items.Contains(cost) {
-				d.exclude = append(d.exclude, bot)
-			}
-		}
-	} else {
-		d.items = Subtract(d.items, d.bp.Costs[d.nextBotType])
+// Describe lists the bots in `order` by name, along with the minute each was built in
+func (s *Scenario) Describe(order []Build) string {
+	return strings.Join(util.Map(order, func(b Build) string {
+		return fmt.Sprintf("%v@%d", s.Name(b.Bot), b.Minute)
+	}), " ")
+}
+
+// buildStep is a node in a factory's build order, where the bot was finished
+// with `remaining` minutes left. The order is stored as a linked list from the
+// latest build back, so that factories branching off each other share the
+// builds they have in common
+type buildStep struct {
+	bot       ResourceType
+	remaining int
+	prev      *buildStep
+}
+
+// Factory is the state of a blueprint's robot factory, with `minute` minutes remaining
+type Factory struct {
+	minute int
+	items  Items
+	bots   Robot
+	last   *buildStep
+}
+
+// factoryKey is a factory without its build order. Factories with the same key
+// can build the same things from then on, so only one needs to be searched
+type factoryKey struct {
+	minute int
+	items  Items
+	bots   Robot
+}
+
+func (f *Factory) key() factoryKey {
+	return factoryKey{f.minute, f.items, f.bots}
+}
+
+// Collected returns how much of resource `t` the factory will end up with if no
+// more bots are built
+func (f *Factory) Collected(t ResourceType) int {
+	return f.items[t] + f.bots[t]*f.minute
+}
+
+// BuildOrder returns the bots the factory built in order, where the factory
+// started with `minutes` minutes
+func (f *Factory) BuildOrder(minutes int) []Build {
+	var order []Build
+	for step := f.last; step != nil; step = step.prev {
+		order = append(order, Build{step.bot, minutes - step.remaining})
 	}
+	for i, j := 0, len(order)-1; i < j; i, j = i+1, j-1 {
+		order[i], order[j] = order[j], order[i]
+	}
+	return order
+}
 
-	d.items = Add(d.items, d.bots)
-	if d.nextBotType != None {
-		d.bots.Add(d.nextBotType, 1)
+// GetWaitTime returns how many minutes the factory needs to collect enough items
+// to start building `bot`, or false if it doesn't have the bots to ever collect them
+func GetWaitTime(bp Blueprint, f Factory, bot ResourceType) (int, bool) {
+	cost := bp.Costs[bot]
+	wait := 0
+	for res := range cost {
+		need := cost[res] - f.items[res]
+		if need <= 0 {
+			continue
+		}
+		rate := f.bots[res]
+		if rate == 0 {
+			return 0, false
+		}
+		wait = util.Max(wait, (need+rate-1)/rate)
 	}
-	d.minute--
+	return wait, true
+}
 
-	return Decide(d)
+// Optimiser searches for the best way to collect a scenario's objective with a blueprint
+type Optimiser struct {
+	scenario Scenario
+	bp       Blueprint
+	priority []ResourceType // The bots that can be built, in the order to try them
+	maxSpend Items
 }
 
-func Decide(d DecisionFactors) Items {
-	if d.minute == 0 {
-		return d.items
+func NewOptimiser(s Scenario, bp Blueprint) *Optimiser {
+	o := &Optimiser{scenario: s, bp: bp, maxSpend: s.MaxSpend(bp)}
+
+	// Try the objective first, then the rest in reverse order since resources
+	// mentioned later are usually made from the earlier ones
+	if _, ok := bp.Costs[s.Objective]; ok {
+		o.priority = append(o.priority, s.Objective)
 	}
-	if d.items.Geode+d.bots.Geode*d.minute+SumN(d.minute-1) <= d.curMax.Geode {
-		// curMax is unbeatable even if we make geode bots for the remaining time; prune this branch
-		return d.items
+	for r := len(s.Resources) - 1; r >= 0; r-- {
+		if _, ok := bp.Costs[ResourceType(r)]; ok && ResourceType(r) != s.Objective {
+			o.priority = append(o.priority, ResourceType(r))
+		}
 	}
+	return o
+}
+
+// NextBuilds returns the factories resulting from waiting until each type of bot
+// can be built, and then building it. Bots that would finish too late to be of any
+// use, or that collect more than could ever be spent per minute, are skipped
+func (o *Optimiser) NextBuilds(f Factory) []Factory {
+	var next []Factory
 
-	// Intentionally reverse order to prioritize most useful bots
-	for _, bot := range []ResourceType{Geode, Obsidian, Clay, Ore, None} {
-		if slices.Contains(d.exclude, bot) || !d.items.Contains(d.bp.Costs[bot]) {
+	for _, bot := range o.priority {
+		if bot != o.scenario.Objective && f.bots[bot] >= o.maxSpend[bot] {
 			continue
 		}
-		d.nextBotType = bot
-		test := Simulate(d)
-		if test.Geode > d.curMax.Geode {
-			d.curMax = test
+
+		wait, ok := GetWaitTime(o.bp, f, bot)
+		if !ok || wait+1 >= f.minute {
+			// A bot finishing in the last minute wouldn't collect anything
+			continue
 		}
+
+		n := f
+		n.items = Subtract(Add(f.items, Scale(f.bots, wait+1)), o.bp.Costs[bot])
+		n.bots[bot]++
+		n.minute -= wait + 1
+		n.last = &buildStep{bot, n.minute, f.last}
+		next = append(next, n)
 	}
+	return next
+}
 
-	return d.curMax
+// Plan is the most of the objective a blueprint can collect, and the bots built to get there
+type Plan struct {
+	Collected int
+	Order     []Build
 }
 
-func GetMaxGeodes(bp Blueprint, minute int) int {
-	return Decide(DecisionFactors{
-		bp:          bp,
-		minute:      minute,
-		items:       Items{Ore: 0, Clay: 0, Obsidian: 0, Geode: 0},
-		bots:        Robot{Ore: 1, Clay: 0, Obsidian: 0, Geode: 0},
-		nextBotType: None,
-		curMax:      Items{Ore: 0, Clay: 0, Obsidian: 0, Geode: 0},
-		exclude:     []ResourceType{},
-	}).Geode
+// Run finds the most of the objective that can be collected in `minutes` minutes.
+// It gives up and returns the context's error if `ctx` is cancelled
+func (o *Optimiser) Run(ctx context.Context, minutes int) (Plan, error) {
+	objective := o.scenario.Objective
+	result := search.Maximize(search.Problem[Factory, factoryKey]{
+		Initial: Factory{minute: minutes, bots: o.scenario.StartBots},
+		Successors: func(f Factory) []Factory {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return o.NextBuilds(f)
+		},
+		Objective: func(f Factory) int { return f.Collected(objective) },
+		// Even if we made objective bots for the remaining time, we can't beat this
+		Bound: func(f Factory) int { return f.Collected(objective) + SumN(f.minute-1) },
+		Key:   func(f Factory) factoryKey { return f.key() },
+	}, search.Options{Order: search.DepthFirst})
+
+	if err := ctx.Err(); err != nil {
+		return Plan{}, err
+	}
+
+	return Plan{result.Value, result.Best.BuildOrder(minutes)}, nil
+}
+
+// getPlans finds the best plan for each of `blueprints` in parallel
+func getPlans(s Scenario, blueprints []Blueprint, minutes int) []Plan {
+	plans, err := pool.Map(
+		context.Background(),
+		blueprints,
+		0,
+		func(ctx context.Context, bp Blueprint) (Plan, error) {
+			return NewOptimiser(s, bp).Run(ctx, minutes)
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+	return plans
+}
+
+// TraceEnvVar is the environment variable that turns on printing of the best build
+// order for each blueprint when set to "1"
+const TraceEnvVar = "AOC_TRACE"
+
+func logPlans(s Scenario, blueprints []Blueprint, plans []Plan, part int) {
+	if os.Getenv(TraceEnvVar) != "1" {
+		return
+	}
+	for i, plan := range plans {
+		log.Infow("Best build order", "blueprint", blueprints[i].ID, "geodes", plan.Collected, "order", s.Describe(plan.Order), "part", part)
+	}
 }
 
 func PartOne() any {
-	total := 0
-	var wg sync.WaitGroup
+	s, blueprints := getPartOneData()
+	plans := getPlans(s, blueprints, 24)
+	logPlans(s, blueprints, plans, 1)
 
-	for _, bp := range getPartOneData() {
-		bp := bp
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			total += GetMaxGeodes(bp, 24) * bp.ID
-		}()
+	total := 0
+	for i, plan := range plans {
+		total += plan.Collected * blueprints[i].ID
 	}
-	wg.Wait()
 	return total
 }
 
 func PartTwo() any {
-	const max = 3
-	numGeodes := make([]int, max)
-	var wg sync.WaitGroup
-
-	for _, bp := range getPartOneData()[:max] {
-		bp := bp
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			numGeodes[bp.ID-1] = GetMaxGeodes(bp, 32)
-		}()
-	}
-	wg.Wait()
-	return numGeodes[0] * numGeodes[1] * numGeodes[2]
+	s, blueprints := getPartOneData()
+	plans := getPlans(s, blueprints[:3], 32)
+	logPlans(s, blueprints, plans, 2)
+	return plans[0].Collected * plans[1].Collected * plans[2].Collected
 }
 
 func main() {
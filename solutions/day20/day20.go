@@ -4,6 +4,7 @@ import (
 	"embed"
 	"fmt"
 
+	ds "github.com/ShajeshJ/adventofcode_2022/common/datastructures"
 	"github.com/ShajeshJ/adventofcode_2022/common/logging"
 	"github.com/ShajeshJ/adventofcode_2022/common/util"
 	"golang.org/x/exp/slices"
@@ -14,72 +15,48 @@ var log = logging.GetLogger()
 //go:embed input.txt
 var files embed.FS
 
-type Node struct {
-	Prev  *Node
-	Next  *Node
-	Value int
-}
-
-func getPartOneData() []*Node {
-	var nodes = make([]*Node, 0)
-	for _, line := range util.ReadProblemInput(files) {
-		nodes = append(nodes, &Node{Value: util.AtoiNoError(line)})
-	}
-
-	for i := 1; i < len(nodes); i++ {
-		nodes[i].Prev = nodes[i-1]
-		nodes[i-1].Next = nodes[i]
-	}
-	nodes[0].Prev = nodes[len(nodes)-1]
-	nodes[len(nodes)-1].Next = nodes[0]
-
-	return nodes
-}
-
-func Swap(a, b *Node) {
-	a.Prev.Next = b
-	b.Next.Prev = a
-	a.Next = b.Next
-	b.Prev = a.Prev
-	a.Prev = b
-	b.Next = a
+func getPartOneData() []int {
+	return util.Map(util.ReadProblemInput(files), util.AtoiNoError)
 }
 
 func RunDecryption(decryptKey, numMixes int) []int {
 	data := getPartOneData()
 
 	// Apply decryption key
-	for _, n := range data {
-		n.Value *= decryptKey
+	for i := range data {
+		var err error
+		if data[i], err = util.CheckedMul(data[i], decryptKey); err != nil {
+			panic(err)
+		}
 	}
 
-	for i := 0; i < numMixes; i++ {
-		for _, n := range data {
-			shift := n.Value % (len(data) - 1) // modulo to ignore complete loops around the list
-			for shift != 0 {
-				if shift > 0 {
-					Swap(n, n.Next)
-					shift--
-				} else {
-					Swap(n.Prev, n)
-					shift++
-				}
+	// Rotate the output so that it starts from 0
+	mixed := Mix(data, numMixes)
+	zero := slices.Index(mixed, 0)
+	return append(mixed[zero:], mixed[:zero]...)
+}
 
-			}
-		}
+// Mix moves each number forward or backward by its value, in the order they
+// originally appeared, `numMixes` times. The numbers are treated as a loop, so
+// the output starts from whichever number was originally first
+func Mix(data []int, numMixes int) []int {
+	if len(data) <= 1 {
+		return append([]int(nil), data...)
 	}
 
-	idx := slices.IndexFunc(data, func(n *Node) bool { return n.Value == 0 })
-	start := data[idx]
-	encrypted := []int{start.Value}
-
-	next := start.Next
-	for next != start {
-		encrypted = append(encrypted, next.Value)
-		next = next.Next
+	mixed, nodes := ds.TreapFromSlice(data)
+	for i := 0; i < numMixes; i++ {
+		for _, n := range nodes {
+			// Once the number is taken out, the other numbers form a loop of
+			// len(data)-1, so the new index wraps around that
+			idx := util.Mod(mixed.IndexOf(n)+n.Value, len(data)-1)
+			mixed.MoveTo(n, idx)
+		}
 	}
 
-	return encrypted
+	output := mixed.ToSlice()
+	first := mixed.IndexOf(nodes[0])
+	return append(output[first:], output[:first]...)
 }
 
 func PartOne() any {
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+
+	ds "github.com/ShajeshJ/adventofcode_2022/common/datastructures"
+	"golang.org/x/exp/slices"
+)
+
+// mixRing is the straightforward mixing, walking each number around a linked
+// ring, which `Mix` is checked against
+func mixRing(data []int, numMixes int) []int {
+	ring, nodes := ds.RingFromSlice(data)
+	for i := 0; i < numMixes; i++ {
+		for _, n := range nodes {
+			ring.Move(n, n.Value)
+		}
+	}
+	if len(nodes) == 0 {
+		return []int{}
+	}
+	return ring.ToSlice(nodes[0])
+}
+
+func TestMix(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []int
+		numMixes int
+	}{
+		{"empty", []int{}, 1},
+		{"single", []int{5}, 3},
+		{"pair", []int{1, -7}, 2},
+		{"example", []int{1, 2, -3, 3, -2, 0, 4}, 1},
+		{"example mixed ten times", []int{811589153, 1623178306, -2434767459, 2434767459, -1623178306, 0, 3246356612}, 10},
+		{"all zeros", []int{0, 0, 0, 0}, 2},
+		{"duplicates", []int{3, 3, -3, 0, 3, -3}, 3},
+		// With 6 numbers, multiples of 5 should leave a number where it is
+		{"multiples of len-1", []int{5, -10, 0, 500, -5_000_000_000, 1}, 2},
+		{"one off multiples of len-1", []int{6, -11, 0, 501, -4_999_999_999, 4}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := mixRing(tt.data, tt.numMixes)
+			if got := Mix(tt.data, tt.numMixes); !slices.Equal(got, want) {
+				t.Errorf("Mix(%v, %d) = %v, want %v", tt.data, tt.numMixes, got, want)
+			}
+		})
+	}
+}
+
+func TestMixExample(t *testing.T) {
+	got := Mix([]int{1, 2, -3, 3, -2, 0, 4}, 1)
+	if want := []int{1, 2, -3, 4, 0, 3, -2}; !slices.Equal(got, want) {
+		t.Errorf("Mix(example) = %v, want %v", got, want)
+	}
+}
+
+func TestMixRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		data := make([]int, 1+rng.Intn(40))
+		for j := range data {
+			switch rng.Intn(4) {
+			case 0:
+				data[j] = 0
+			case 1:
+				// A large multiple of len-1, give or take a little
+				data[j] = (rng.Intn(2_000_001)-1_000_000)*(len(data)-1) + rng.Intn(3) - 1
+			default:
+				data[j] = rng.Intn(201) - 100
+			}
+		}
+		numMixes := 1 + rng.Intn(3)
+
+		want := mixRing(data, numMixes)
+		if got := Mix(data, numMixes); !slices.Equal(got, want) {
+			t.Fatalf("Mix(%v, %d) = %v, want %v", data, numMixes, got, want)
+		}
+	}
+}
@@ -3,9 +3,13 @@ package main
 import (
 	"embed"
 	"fmt"
+	"math/big"
+	"os"
 	"strings"
 
 	"github.com/ShajeshJ/adventofcode_2022/common/logging"
+	"github.com/ShajeshJ/adventofcode_2022/common/num"
+	"github.com/ShajeshJ/adventofcode_2022/common/parse"
 	"github.com/ShajeshJ/adventofcode_2022/common/util"
 )
 
@@ -23,165 +27,209 @@ const (
 
 type Operator int
 
-type Monkey struct {
-	IsVal     bool
-	IsUnknown bool
-	Val       int
-	Left      string
-	Right     string
-	Op        Operator
+type Monkey[T any] struct {
+	IsVal bool
+	Val   T
+	Left  string
+	Right string
+	Op    Operator
 }
 
-func getPartOneData() map[string]*Monkey {
-	OpLookup := map[string]Operator{
-		"+": Add,
-		"-": Subtract,
-		"*": Multiply,
-		"/": Divide,
-	}
-
-	monkeys := make(map[string]*Monkey)
-	for _, line := range util.ReadProblemInput(files) {
-		tokens := strings.Split(line, " ")
-		name := strings.TrimRight(tokens[0], ":")
-		if len(tokens) == 2 {
-			monkeys[name] = &Monkey{IsVal: true, Val: util.AtoiNoError(tokens[1])}
-		} else {
-			monkeys[name] = &Monkey{IsVal: false, Left: tokens[1], Right: tokens[3], Op: OpLookup[tokens[2]]}
+var opLookup = map[string]Operator{
+	"+": Add,
+	"-": Subtract,
+	"*": Multiply,
+	"/": Divide,
+}
+
+// ParseMonkeys reads lines of either "name: value" or "name: left op right"
+func ParseMonkeys[T any](a num.Arith[T], lines []string) (map[string]*Monkey[T], error) {
+	monkeys := make(map[string]*Monkey[T])
+	for i, line := range lines {
+		tokens := strings.Fields(line)
+		if len(tokens) == 0 {
+			continue
+		}
+		wrap := func(err error) error { return &parse.Error{Line: i + 1, Err: err} }
+
+		name := strings.TrimSuffix(tokens[0], ":")
+		if name == tokens[0] || name == "" {
+			return nil, wrap(fmt.Errorf("expected a monkey name followed by ':', got %q", tokens[0]))
+		}
+		if _, ok := monkeys[name]; ok {
+			return nil, wrap(fmt.Errorf("monkey %q is defined more than once", name))
+		}
+
+		switch len(tokens) {
+		case 2:
+			val, err := a.Parse(tokens[1])
+			if err != nil {
+				return nil, wrap(err)
+			}
+			monkeys[name] = &Monkey[T]{IsVal: true, Val: val}
+		case 4:
+			op, ok := opLookup[tokens[2]]
+			if !ok {
+				return nil, wrap(fmt.Errorf("unknown operator %q", tokens[2]))
+			}
+			monkeys[name] = &Monkey[T]{Left: tokens[1], Right: tokens[3], Op: op}
+		default:
+			return nil, wrap(fmt.Errorf("expected \"name: value\" or \"name: left op right\", got %q", line))
 		}
 	}
+	return monkeys, nil
+}
 
-	return monkeys
+// RequireMonkeys returns an error if any of `names` isn't one of the monkeys
+func RequireMonkeys[T any](monkeys map[string]*Monkey[T], names ...string) error {
+	for _, name := range names {
+		if _, ok := monkeys[name]; !ok {
+			return fmt.Errorf("missing monkey %q", name)
+		}
+	}
+	return nil
 }
 
-func Compute(m *Monkey, monkeys map[string]*Monkey) int {
-	if m.IsVal {
-		return m.Val
+// getPartOneData returns the monkeys, along with the order to evaluate them in.
+// It panics if the input is invalid, or any of `required` are missing
+func getPartOneData[T any](a num.Arith[T], required ...string) (map[string]*Monkey[T], []string) {
+	monkeys, err := ParseMonkeys(a, util.ReadProblemInput(files))
+	if err != nil {
+		panic(err)
+	}
+	if err := RequireMonkeys(monkeys, required...); err != nil {
+		panic(err)
 	}
 
-	left := monkeys[m.Left]
-	right := monkeys[m.Right]
+	order, err := EvalOrder(monkeys)
+	if err != nil {
+		panic(err)
+	}
+	return monkeys, order
+}
 
-	switch m.Op {
+// Apply evaluates `left op right` using `a`. Any error, such as an inexact division,
+// panics since the answer would be wrong
+func Apply[T any](a num.Arith[T], left T, op Operator, right T) T {
+	switch op {
 	case Add:
-		return Compute(left, monkeys) + Compute(right, monkeys)
+		return num.Must(a.Add(left, right))
 	case Subtract:
-		return Compute(left, monkeys) - Compute(right, monkeys)
+		return num.Must(a.Sub(left, right))
 	case Multiply:
-		return Compute(left, monkeys) * Compute(right, monkeys)
+		return num.Must(a.Mul(left, right))
 	case Divide:
-		return Compute(left, monkeys) / Compute(right, monkeys)
+		return num.Must(a.Div(left, right))
 	}
 
 	panic("Invalid operator")
 }
 
-func PartOne() any {
-	monkeys := getPartOneData()
-	return Compute(monkeys["root"], monkeys)
-}
-
-type PartialResult struct {
-	IsUnknown bool
-	Val       int
-	Left      *PartialResult
-	Op        Operator
-	Right     *PartialResult
+func partOne[T any](a num.Arith[T]) string {
+	monkeys, order := getPartOneData(a, "root")
+	return a.String(Evaluate(a, monkeys, order)["root"])
 }
 
-func ComputePartial(m *Monkey, monkeys map[string]*Monkey) *PartialResult {
-	if m.IsUnknown {
-		return &PartialResult{IsUnknown: true}
-	}
-	if m.IsVal {
-		return &PartialResult{Val: m.Val}
+func PartOne() any {
+	if num.UseBig() {
+		return partOne[*big.Rat](num.Rat{})
 	}
+	return partOne[int](num.Int{})
+}
 
-	result := &PartialResult{}
-	result.Left = ComputePartial(monkeys[m.Left], monkeys)
-	result.Right = ComputePartial(monkeys[m.Right], monkeys)
-	result.Op = m.Op
-	result.IsUnknown = result.Left.IsUnknown || result.Right.IsUnknown
-
-	if result.IsUnknown {
-		return result
+// BuildExprs returns the expression that each monkey yells, with `unknown` left as
+// a variable and everything that doesn't depend on it folded into constants.
+// Expressions are built in `order`, so that each monkey's is put together from
+// ones that have already been built
+func BuildExprs(monkeys map[string]*Monkey[*big.Rat], order []string, unknown string) (map[string]*Expr, error) {
+	exprs := make(map[string]*Expr, len(order))
+	for _, name := range order {
+		m := monkeys[name]
+		switch {
+		case name == unknown:
+			exprs[name] = Var(name)
+		case m.IsVal:
+			exprs[name] = Const(m.Val)
+		default:
+			e, err := Fold(exprs[m.Left], m.Op, exprs[m.Right])
+			if err != nil {
+				return nil, fmt.Errorf("monkey %q: %w", name, err)
+			}
+			exprs[name] = e
+		}
 	}
+	return exprs, nil
+}
 
-	switch m.Op {
-	case Add:
-		result.Val = result.Left.Val + result.Right.Val
-	case Subtract:
-		result.Val = result.Left.Val - result.Right.Val
-	case Multiply:
-		result.Val = result.Left.Val * result.Right.Val
-	case Divide:
-		result.Val = result.Left.Val / result.Right.Val
+// LinearForms returns what each monkey yells in the form `a*x + b`, where x is
+// `unknown`. Like `Evaluate`, it works through the monkeys in `order`, so each
+// monkey's form is worked out once and reused by every monkey listening to it
+func LinearForms(monkeys map[string]*Monkey[*big.Rat], order []string, unknown string) (map[string]Linear, error) {
+	forms := make(map[string]Linear, len(order))
+	for _, name := range order {
+		m := monkeys[name]
+		switch {
+		case name == unknown:
+			forms[name] = LinearVar()
+		case m.IsVal:
+			forms[name] = LinearConst(m.Val)
+		default:
+			l, err := CombineLinear(forms[m.Left], m.Op, forms[m.Right])
+			if err != nil {
+				return nil, fmt.Errorf("monkey %q: %w", name, err)
+			}
+			forms[name] = l
+		}
 	}
-
-	return result
+	return forms, nil
 }
 
-func SolveStep(target int, equation *PartialResult) int {
-	if equation.Left == nil && equation.Right == nil {
-		return target
+func PartTwo() any {
+	monkeys, order := getPartOneData[*big.Rat](num.Rat{}, "root", "humn")
+	root := monkeys["root"]
+	if root.IsVal {
+		panic("root must compare two monkeys, but it yells a number")
 	}
 
-	var unknown *PartialResult
-
-	if equation.Left.IsUnknown {
-		unknown = equation.Left
-		switch equation.Op {
-		case Add:
-			target -= equation.Right.Val
-		case Subtract:
-			target += equation.Right.Val
-		case Multiply:
-			target /= equation.Right.Val
-		case Divide:
-			target *= equation.Right.Val
-		}
-	} else {
-		unknown = equation.Right
-		switch equation.Op {
-		case Add:
-			target -= equation.Left.Val
-		case Subtract:
-			target = equation.Left.Val - target
-		case Multiply:
-			target /= equation.Left.Val
-		case Divide:
-			target = equation.Left.Val / target
+	if os.Getenv(TraceEnvVar) == "1" {
+		exprs, err := BuildExprs(monkeys, order, "humn")
+		if err != nil {
+			panic(err)
 		}
+		log.Infow("Simplified equation", "root", Equation{exprs[root.Left], exprs[root.Right]}.String(), "part", 2)
 	}
 
-	return SolveStep(target, unknown)
-}
-
-// SolveEquation solves by assuming there is exactly 1 deeply nested unknown to unwrap solve naively
-func SolveEquation(left, right *PartialResult) int {
-	var target, unknown *PartialResult
-	if left.IsUnknown {
-		unknown = left
-		target = right
-	} else {
-		unknown = right
-		target = left
+	forms, err := LinearForms(monkeys, order, "humn")
+	if err != nil {
+		panic(err)
 	}
-
-	return SolveStep(target.Val, unknown)
+	x, err := SolveLinear(forms[root.Left], forms[root.Right])
+	if err != nil {
+		panic(err)
+	}
+	return x.RatString()
 }
 
-func PartTwo() any {
-	monkeys := getPartOneData()
-	monkeys["humn"].IsUnknown = true
+// writeGraph writes the monkeys' dependency graph to the file at `path`
+func writeGraph(path string) {
+	monkeys, order := getPartOneData[*big.Rat](num.Rat{})
 
-	left := ComputePartial(monkeys[monkeys["root"].Left], monkeys)
-	right := ComputePartial(monkeys[monkeys["root"].Right], monkeys)
+	f, err := os.Create(path)
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
 
-	return SolveEquation(left, right)
+	if err := WriteDOT[*big.Rat](f, num.Rat{}, monkeys, order, "root", "humn"); err != nil {
+		panic(err)
+	}
+	log.Infow("Wrote monkey graph", "path", path)
 }
 
 func main() {
+	if path := os.Getenv(DotEnvVar); path != "" {
+		writeGraph(path)
+	}
 	log.Infow(fmt.Sprintf("Answer: %v", PartOne()), "part", 1)
 	log.Infow(fmt.Sprintf("Answer: %v", PartTwo()), "part", 2)
 }
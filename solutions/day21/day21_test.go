@@ -0,0 +1,200 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ShajeshJ/adventofcode_2022/common/num"
+	"github.com/ShajeshJ/adventofcode_2022/common/util"
+)
+
+func TestParseMonkeysErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  string
+	}{
+		{"unknown operator", []string{"root: a % b"}, `line 1: unknown operator "%"`},
+		{"too few tokens", []string{"a: 1", "root: a +"}, "line 2: expected"},
+		{"too many tokens", []string{"root: a + b c"}, "line 1: expected"},
+		{"missing colon", []string{"root 5"}, "line 1: expected a monkey name"},
+		{"bad number", []string{"root: five"}, "line 1:"},
+		{"duplicate", []string{"a: 1", "a: 2"}, `line 2: monkey "a" is defined more than once`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseMonkeys[int](num.Int{}, tt.lines)
+			if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+				t.Errorf("ParseMonkeys(%q) error = %v, want prefix %q", tt.lines, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGraphErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []string
+		required []string
+		want     string
+	}{
+		{"missing root", []string{"a: 1"}, []string{"root"}, `missing monkey "root"`},
+		{"missing humn", []string{"root: a + a", "a: 1"}, []string{"root", "humn"}, `missing monkey "humn"`},
+		{"unknown reference", []string{"root: a + b", "a: 1"}, nil, `unknown monkeys: "b" (needed by "root")`},
+		{"cycle", []string{"root: a + b", "a: b * c", "b: a - c", "c: 1"}, nil, "monkeys wait on each other in a cycle: a -> b -> a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monkeys, err := ParseMonkeys[int](num.Int{}, tt.lines)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = RequireMonkeys(monkeys, tt.required...); err == nil {
+				_, err = EvalOrder(monkeys)
+			}
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+var exampleLines = strings.Split(`root: pppw + sjmn
+dbpl: 5
+cczh: sllz + lgvd
+zczc: 2
+ptdq: humn - dvpt
+dvpt: 3
+lfqf: 4
+humn: 5
+ljgn: 2
+sjmn: drzm * dbpl
+sllz: 4
+pppw: cczh / lfqf
+lgvd: ljgn * ptdq
+drzm: hmdt - zczc
+hmdt: 32`, "\n")
+
+func TestEvaluate(t *testing.T) {
+	monkeys, err := ParseMonkeys[int](num.Int{}, exampleLines)
+	if err != nil {
+		t.Fatal(err)
+	}
+	order, err := EvalOrder(monkeys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := Evaluate[int](num.Int{}, monkeys, order)["root"]; got != 152 {
+		t.Errorf("root = %d, want 152", got)
+	}
+}
+
+// solveFor parses `lines`, and solves for `unknown` where both sides of root are
+// equal. The unknown is given a placeholder value if `lines` doesn't have one
+func solveFor(t *testing.T, lines []string, unknown string) (*big.Rat, error) {
+	t.Helper()
+	monkeys, err := ParseMonkeys[*big.Rat](num.Rat{}, lines)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := monkeys[unknown]; !ok {
+		monkeys[unknown] = &Monkey[*big.Rat]{IsVal: true, Val: new(big.Rat)}
+	}
+	order, err := EvalOrder(monkeys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	forms, err := LinearForms(monkeys, order, unknown)
+	if err != nil {
+		return nil, err
+	}
+	return SolveLinear(forms[monkeys["root"].Left], forms[monkeys["root"].Right])
+}
+
+func TestSolve(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  string
+		err   error
+	}{
+		{"example", exampleLines, "301", nil},
+		{"both sides", []string{"root: a = b", "a: humn * three", "b: humn + four", "three: 3", "four: 4"}, "2", nil},
+		{"fraction", []string{"root: a = one", "a: humn * three", "three: 3", "one: 1"}, "1/3", nil},
+		{"repeated", []string{"root: a = ten", "a: humn + humn", "ten: 10"}, "5", nil},
+		{"non-linear", []string{"root: a = ten", "a: humn * humn", "ten: 10"}, "", ErrNonLinear},
+		{"divided by unknown", []string{"root: a = ten", "a: ten / humn", "ten: 10"}, "", ErrNonLinear},
+		{"no solution", []string{"root: humn = a", "a: humn + one", "one: 1"}, "", ErrNoSolution},
+		{"any solution", []string{"root: humn = a", "a: humn * one", "one: 1"}, "", ErrAnySolution},
+		{"divide by zero", []string{"root: humn = a", "a: one / zero", "one: 1", "zero: 0"}, "", num.ErrDivByZero},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// "=" isn't an operator, so root compares with "+" like in the input
+			lines := util.Map(tt.lines, func(s string) string { return strings.Replace(s, " = ", " + ", 1) })
+
+			got, err := solveFor(t, lines, "humn")
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("error = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil || got.RatString() != tt.want {
+				t.Errorf("humn = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+// TestSolveSharedChain has each monkey listen to the previous one twice. Walking
+// every path would take 2^depth steps, but each monkey is only visited once
+func TestSolveSharedChain(t *testing.T) {
+	const depth = 200
+	lines := []string{"m0: humn - one", "one: 1", "two: 2", fmt.Sprintf("root: m%d + two", depth)}
+	for i := 1; i <= depth; i++ {
+		// m_i = (m_{i-1} + m_{i-1}) / 2 = m_{i-1}
+		lines = append(lines, fmt.Sprintf("s%d: m%d + m%d", i, i-1, i-1), fmt.Sprintf("m%d: s%d / two", i, i))
+	}
+
+	got, err := solveFor(t, lines, "humn")
+	if err != nil || got.RatString() != "3" {
+		t.Errorf("humn = %v, %v, want 3", got, err)
+	}
+}
+
+func TestExprString(t *testing.T) {
+	x, c := Var("x"), func(n int64) *Expr { return Const(big.NewRat(n, 1)) }
+	tests := []struct {
+		expr *Expr
+		want string
+	}{
+		{BinOp(BinOp(x, Add, c(1)), Multiply, c(2)), "(x + 1) * 2"},
+		{BinOp(c(2), Multiply, BinOp(x, Add, c(1))), "2 * (x + 1)"},
+		{BinOp(BinOp(x, Multiply, c(2)), Add, c(1)), "x * 2 + 1"},
+		{BinOp(c(1), Subtract, BinOp(x, Subtract, c(2))), "1 - (x - 2)"},
+		{BinOp(BinOp(c(1), Subtract, x), Subtract, c(2)), "1 - x - 2"},
+		{BinOp(c(1), Add, BinOp(x, Add, c(2))), "1 + x + 2"},
+		{BinOp(c(8), Divide, BinOp(x, Multiply, c(2))), "8 / (x * 2)"},
+		{Const(big.NewRat(1, 3)), "1/3"},
+		{Const(big.NewRat(-3, 1)), "-3"},
+		// Fractions and negative numbers are bracketed wherever they're an operand
+		{BinOp(x, Divide, Const(big.NewRat(1, 3))), "x / (1/3)"},
+		{BinOp(x, Subtract, c(-3)), "x - (-3)"},
+		{BinOp(c(-3), Multiply, x), "(-3) * x"},
+		{BinOp(BinOp(x, Add, Const(big.NewRat(-1, 2))), Multiply, c(2)), "(x + (-1/2)) * 2"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.expr.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
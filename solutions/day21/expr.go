@@ -0,0 +1,232 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ShajeshJ/adventofcode_2022/common/num"
+)
+
+var (
+	// ErrNonLinear is returned when the unknown is multiplied by itself, or divided by
+	ErrNonLinear = errors.New("expression is not linear in the unknown")
+	// ErrNoSolution is returned for equations like x = x + 1
+	ErrNoSolution = errors.New("equation has no solution")
+	// ErrAnySolution is returned for equations like x = x, where every value works
+	ErrAnySolution = errors.New("equation holds for every value")
+)
+
+func (o Operator) String() string {
+	return [...]string{"+", "-", "*", "/"}[o]
+}
+
+// precedence returns how tightly `o` binds, for deciding where brackets are needed
+func (o Operator) precedence() int {
+	if o == Add || o == Subtract {
+		return 1
+	}
+	return 2
+}
+
+// Expr is a node in an expression tree. Leaves are either a constant, or the named
+// unknown; every other node applies `Op` to its two children. Children may be
+// shared between several parents, as monkeys can be listened to by more than one
+type Expr struct {
+	Val     *big.Rat
+	Unknown string
+	Op      Operator
+	Left    *Expr
+	Right   *Expr
+}
+
+func Const(val *big.Rat) *Expr {
+	return &Expr{Val: val}
+}
+
+func Var(name string) *Expr {
+	return &Expr{Unknown: name}
+}
+
+func BinOp(left *Expr, op Operator, right *Expr) *Expr {
+	return &Expr{Op: op, Left: left, Right: right}
+}
+
+// Fold is BinOp, except that two constants are combined into a single constant,
+// so that only the parts involving the unknown are kept as a tree
+func Fold(left *Expr, op Operator, right *Expr) (*Expr, error) {
+	if left.Val == nil || right.Val == nil {
+		return BinOp(left, op, right), nil
+	}
+
+	val, err := applyRat(left.Val, op, right.Val)
+	if err != nil {
+		return nil, err
+	}
+	return Const(val), nil
+}
+
+func (e *Expr) IsLeaf() bool {
+	return e.Left == nil
+}
+
+// needsBrackets returns whether `e` must be bracketed as an operand of an `op` node,
+// on the given side of it
+func (e *Expr) needsBrackets(op Operator, right bool) bool {
+	if e.IsLeaf() {
+		// Otherwise x / 1/3 and x - -3 would be ambiguous, or at least hard to read
+		return e.Val != nil && (!e.Val.IsInt() || e.Val.Sign() < 0)
+	}
+	if right {
+		// Subtraction and division aren't associative, so a - (b - c) keeps its brackets
+		return e.Op.precedence() < op.precedence() ||
+			e.Op.precedence() == op.precedence() && (op == Subtract || op == Divide)
+	}
+	return e.Op.precedence() < op.precedence()
+}
+
+// printItem is either an expression still to be written, or some literal text
+type printItem struct {
+	expr     *Expr
+	text     string
+	brackets bool
+}
+
+// String renders the expression with the fewest brackets needed to keep its meaning.
+// It uses its own stack rather than recursion, so long chains can't overflow it
+func (e *Expr) String() string {
+	var sb strings.Builder
+	stack := []printItem{{expr: e}}
+	for len(stack) > 0 {
+		item := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		switch {
+		case item.expr == nil:
+			sb.WriteString(item.text)
+		case item.expr.Unknown != "":
+			sb.WriteString(item.expr.Unknown)
+		case item.expr.IsLeaf() && item.brackets:
+			fmt.Fprintf(&sb, "(%v)", item.expr.Val.RatString())
+		case item.expr.IsLeaf():
+			sb.WriteString(item.expr.Val.RatString())
+		default:
+			n := item.expr
+			if item.brackets {
+				sb.WriteByte('(')
+				stack = append(stack, printItem{text: ")"})
+			}
+
+			// Pushed in reverse, so the left side is written first
+			stack = append(stack,
+				printItem{expr: n.Right, brackets: n.Right.needsBrackets(n.Op, true)},
+				printItem{text: fmt.Sprintf(" %v ", n.Op)},
+				printItem{expr: n.Left, brackets: n.Left.needsBrackets(n.Op, false)},
+			)
+		}
+	}
+	return sb.String()
+}
+
+// Linear is the expression `A*x + B`
+type Linear struct {
+	A *big.Rat
+	B *big.Rat
+}
+
+func LinearConst(val *big.Rat) Linear {
+	return Linear{new(big.Rat), val}
+}
+
+// LinearVar is the unknown on its own, i.e. `1*x + 0`
+func LinearVar() Linear {
+	return Linear{big.NewRat(1, 1), new(big.Rat)}
+}
+
+// IsConst returns whether the unknown has been cancelled out
+func (l Linear) IsConst() bool {
+	return l.A.Sign() == 0
+}
+
+func (l Linear) Scale(k *big.Rat) Linear {
+	return Linear{new(big.Rat).Mul(l.A, k), new(big.Rat).Mul(l.B, k)}
+}
+
+func (l Linear) String() string {
+	return fmt.Sprintf("%v*x + %v", l.A.RatString(), l.B.RatString())
+}
+
+// CombineLinear returns the linear form of `left op right`. It returns `ErrNonLinear`
+// if the unknown would end up multiplied by itself, or being divided by
+func CombineLinear(left Linear, op Operator, right Linear) (Linear, error) {
+	switch op {
+	case Add:
+		return Linear{new(big.Rat).Add(left.A, right.A), new(big.Rat).Add(left.B, right.B)}, nil
+	case Subtract:
+		return Linear{new(big.Rat).Sub(left.A, right.A), new(big.Rat).Sub(left.B, right.B)}, nil
+	case Multiply:
+		switch {
+		case left.IsConst():
+			return right.Scale(left.B), nil
+		case right.IsConst():
+			return left.Scale(right.B), nil
+		}
+		return Linear{}, ErrNonLinear
+	case Divide:
+		if !right.IsConst() {
+			return Linear{}, ErrNonLinear
+		}
+		if right.B.Sign() == 0 {
+			return Linear{}, num.ErrDivByZero
+		}
+		return left.Scale(new(big.Rat).Inv(right.B)), nil
+	}
+	panic("Invalid operator")
+}
+
+// SolveLinear finds the value of the unknown where `left` equals `right`, by
+// rearranging them into `a*x + b = 0`
+func SolveLinear(left, right Linear) (*big.Rat, error) {
+	l, err := CombineLinear(left, Subtract, right)
+	if err != nil {
+		return nil, err
+	}
+
+	if l.IsConst() {
+		if l.B.Sign() == 0 {
+			return nil, ErrAnySolution
+		}
+		return nil, ErrNoSolution
+	}
+	x := new(big.Rat).Neg(l.B)
+	return x.Quo(x, l.A), nil
+}
+
+// Equation is `Left = Right`, where the unknown may appear on either side, or both
+type Equation struct {
+	Left  *Expr
+	Right *Expr
+}
+
+func (eq Equation) String() string {
+	return fmt.Sprintf("%v = %v", eq.Left, eq.Right)
+}
+
+// applyRat evaluates `left op right` exactly
+func applyRat(left *big.Rat, op Operator, right *big.Rat) (*big.Rat, error) {
+	switch op {
+	case Add:
+		return new(big.Rat).Add(left, right), nil
+	case Subtract:
+		return new(big.Rat).Sub(left, right), nil
+	case Multiply:
+		return new(big.Rat).Mul(left, right), nil
+	case Divide:
+		if right.Sign() == 0 {
+			return nil, num.ErrDivByZero
+		}
+		return new(big.Rat).Quo(left, right), nil
+	}
+	panic("Invalid operator")
+}
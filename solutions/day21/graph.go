@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/ShajeshJ/adventofcode_2022/common/num"
+)
+
+// TraceEnvVar is the environment variable that turns on printing of the simplified
+// equation for root when set to "1"
+const TraceEnvVar = "AOC_TRACE"
+
+// DotEnvVar is the environment variable holding a file path to write the monkey
+// dependency graph to, in Graphviz DOT format
+const DotEnvVar = "AOC_DOT"
+
+// dependencies returns the monkeys that `m` needs to hear from before it can yell
+func (m *Monkey[T]) dependencies() []string {
+	if m.IsVal {
+		return nil
+	}
+	return []string{m.Left, m.Right}
+}
+
+// EvalOrder validates the monkeys, and returns their names ordered so that every
+// monkey comes after the ones it depends on. It returns an error listing any
+// references to monkeys that don't exist, or a cycle of monkeys waiting on each other
+func EvalOrder[T any](monkeys map[string]*Monkey[T]) ([]string, error) {
+	names := make([]string, 0, len(monkeys))
+	for name := range monkeys {
+		names = append(names, name)
+	}
+	// Sorted so that the order, and any errors, are the same between runs
+	sort.Strings(names)
+
+	var missing []string
+	waiting := make(map[string]int, len(monkeys))
+	dependents := make(map[string][]string, len(monkeys))
+	for _, name := range names {
+		for _, dep := range monkeys[name].dependencies() {
+			if _, ok := monkeys[dep]; !ok {
+				missing = append(missing, fmt.Sprintf("%q (needed by %q)", dep, name))
+				continue
+			}
+			waiting[name]++
+			dependents[dep] = append(dependents[dep], name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("unknown monkeys: %v", strings.Join(missing, ", "))
+	}
+
+	var order []string
+	for _, name := range names {
+		if waiting[name] == 0 {
+			order = append(order, name)
+		}
+	}
+	for i := 0; i < len(order); i++ {
+		for _, d := range dependents[order[i]] {
+			if waiting[d]--; waiting[d] == 0 {
+				order = append(order, d)
+			}
+		}
+	}
+
+	if len(order) < len(monkeys) {
+		return nil, fmt.Errorf("monkeys wait on each other in a cycle: %v", strings.Join(findCycle(monkeys, names, waiting), " -> "))
+	}
+	return order, nil
+}
+
+// findCycle returns a cycle among the monkeys that are still `waiting` on others.
+// Each of them waits on at least one other that is still waiting, so following
+// those dependencies must eventually loop back on itself
+func findCycle[T any](monkeys map[string]*Monkey[T], names []string, waiting map[string]int) []string {
+	var name string
+	for _, n := range names {
+		if waiting[n] > 0 {
+			name = n
+			break
+		}
+	}
+
+	seen := map[string]int{}
+	var path []string
+	for {
+		if i, ok := seen[name]; ok {
+			return append(path[i:], name)
+		}
+		seen[name] = len(path)
+		path = append(path, name)
+
+		for _, dep := range monkeys[name].dependencies() {
+			if waiting[dep] > 0 {
+				name = dep
+				break
+			}
+		}
+	}
+}
+
+// Evaluate returns what every monkey yells, working through them in `order`
+func Evaluate[T any](a num.Arith[T], monkeys map[string]*Monkey[T], order []string) map[string]T {
+	vals := make(map[string]T, len(order))
+	for _, name := range order {
+		m := monkeys[name]
+		if m.IsVal {
+			vals[name] = m.Val
+		} else {
+			vals[name] = Apply(a, vals[m.Left], m.Op, vals[m.Right])
+		}
+	}
+	return vals
+}
+
+// WriteDOT writes the monkeys' dependency graph to `w` in Graphviz DOT format, with
+// an edge from each monkey to the ones it waits on. Monkeys in `highlight` are filled in
+func WriteDOT[T any](w io.Writer, a num.Arith[T], monkeys map[string]*Monkey[T], order []string, highlight ...string) error {
+	highlighted := map[string]bool{}
+	for _, h := range highlight {
+		highlighted[h] = true
+	}
+
+	var sb strings.Builder
+	sb.WriteString("digraph monkeys {\n")
+	for _, name := range order {
+		m := monkeys[name]
+
+		attrs := ""
+		if highlighted[name] {
+			attrs = ", style=filled"
+		}
+		if m.IsVal {
+			fmt.Fprintf(&sb, "\t%q [label=%q, shape=box%v];\n", name, name+": "+a.String(m.Val), attrs)
+			continue
+		}
+
+		fmt.Fprintf(&sb, "\t%q [label=%q%v];\n", name, fmt.Sprintf("%v: %v %v %v", name, m.Left, m.Op, m.Right), attrs)
+		fmt.Fprintf(&sb, "\t%q -> %q [label=\"L\"];\n", name, m.Left)
+		fmt.Fprintf(&sb, "\t%q -> %q [label=\"R\"];\n", name, m.Right)
+	}
+	sb.WriteString("}\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
@@ -3,7 +3,10 @@ package main
 import (
 	"embed"
 	"fmt"
+	"os"
+	"strings"
 
+	ds "github.com/ShajeshJ/adventofcode_2022/common/datastructures"
 	"github.com/ShajeshJ/adventofcode_2022/common/logging"
 	"github.com/ShajeshJ/adventofcode_2022/common/util"
 )
@@ -118,7 +121,14 @@ func buildInstructions(instructionStr string) []Instruction {
 }
 
 func getPartOneData() ([][]*Tile, []Instruction) {
-	data := util.ReadProblemInput(files)
+	return parseInput(util.ReadProblemInput(files))
+}
+
+func parseInput(data []string) ([][]*Tile, []Instruction) {
+	// Ignore trailing blank lines after the instructions
+	for data[len(data)-1] == "" {
+		data = data[:len(data)-1]
+	}
 	instructions := buildInstructions(data[len(data)-1])
 	data = data[:len(data)-2] // Remove non-map data
 
@@ -197,26 +207,121 @@ func getStartingTile(board [][]*Tile) *Tile {
 	panic("No starting tile found")
 }
 
-func PartOne() any {
-	board, instructions := getPartOneData()
+// TraceEnvVar is the environment variable that turns on rendering of the walked
+// route when set to "1", to help spot mistakes in the wraparound
+const TraceEnvVar = "AOC_TRACE"
+
+// Step is a tile that was stood on, and the direction faced while there
+type Step struct {
+	Tile *Tile
+	Dir  Facing
+}
+
+// WalkFunc takes a single step from a tile, returning where it ended up, whether
+// it could move, and the new facing
+type WalkFunc func(curTile *Tile, curDir Facing) (*Tile, bool, Facing)
+
+// FollowPath runs the instructions from the starting tile, moving with `walk`. If
+// `trace` is set, every tile stood on is also returned with its facing, in order
+func FollowPath(board [][]*Tile, instructions []Instruction, walk WalkFunc, trace bool) (*Tile, Facing, []Step) {
 	curTile := getStartingTile(board)
 	var curDir Facing = RIGHT
 
+	var path []Step
+	record := func() {
+		if trace {
+			path = append(path, Step{curTile, curDir})
+		}
+	}
+	record()
+
 	for _, instruction := range instructions {
 		if instruction.IsTurn {
 			curDir = instruction.TurnDir(curDir)
+			record()
 			continue
 		}
 		for i := 0; i < instruction.Steps; i++ {
 			var walked bool
-			curTile, walked = curTile.Walk(curDir)
+			curTile, walked, curDir = walk(curTile, curDir)
 			if !walked {
 				break
 			}
+			record()
+		}
+	}
+
+	return curTile, curDir, path
+}
+
+func getPassword(t *Tile, dir Facing) int {
+	return t.Row*1000 + t.Col*4 + int(dir)
+}
+
+var facingMarkers = map[Facing]rune{RIGHT: '>', DOWN: 'v', LEFT: '<', UP: '^'}
+
+// markPath draws the board as a grid of runes, with the last facing on each
+// tile of `path` drawn over it
+func markPath(board [][]*Tile, path []Step) [][]rune {
+	grid := make([][]rune, len(board))
+	for i, row := range board {
+		grid[i] = make([]rune, len(row))
+		for j, t := range row {
+			grid[i][j] = ' '
+			if t != nil {
+				grid[i][j] = rune(t.Type)
+			}
 		}
 	}
 
-	return curTile.Row*1000 + curTile.Col*4 + int(curDir)
+	for _, step := range path {
+		grid[step.Tile.Row-1][step.Tile.Col-1] = facingMarkers[step.Dir]
+	}
+	return grid
+}
+
+// RenderPath draws the board the same way as the puzzle input, with `>v<^` markers
+// showing where `path` went
+func RenderPath(board [][]*Tile, path []Step) string {
+	var sb strings.Builder
+	for _, row := range markPath(board, path) {
+		sb.WriteString(strings.TrimRight(string(row), " "))
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// RenderFaces draws each face of the cube separately, with `>v<^` markers showing
+// where `path` went, along with where the face sits in the net and which way it points
+func RenderFaces(board [][]*Tile, path []Step) string {
+	grid := markPath(board, path)
+	size := getFaceSize(board)
+
+	var sb strings.Builder
+	for i, f := range FoldCube(board, size) {
+		fmt.Fprintf(&sb, "Face %d (net row %d, col %d, normal %v)\n", i+1, f.NetRow, f.NetCol, f.Normal)
+		for row := f.NetRow * size; row < (f.NetRow+1)*size; row++ {
+			sb.WriteString(string(grid[row][f.NetCol*size : (f.NetCol+1)*size]))
+			sb.WriteByte('\n')
+		}
+	}
+	return sb.String()
+}
+
+func PartOne() any {
+	board, instructions := getPartOneData()
+	trace := os.Getenv(TraceEnvVar) == "1"
+
+	walk := func(curTile *Tile, curDir Facing) (*Tile, bool, Facing) {
+		newTile, walked := curTile.Walk(curDir)
+		return newTile, walked, curDir
+	}
+	curTile, curDir, path := FollowPath(board, instructions, walk, trace)
+
+	if trace {
+		log.Infow("Walked route\n"+RenderPath(board, path), "part", 1)
+	}
+	return getPassword(curTile, curDir)
 }
 
 type Remap struct {
@@ -224,14 +329,157 @@ type Remap struct {
 	dir Facing
 }
 
-// getCubeRemapping manually remaps the direction from a tile to
-// a new tile/direction, such that the map connects like a cube net.
-// Assumes the following cube net, where each # is a cube face of 50x50:
-//
-//	.12
-//	.3.
-//	45.
-//	6..
+// Vec3 is a unit vector along one of the cube's axes
+type Vec3 [3]int
+
+func (v Vec3) Neg() Vec3 {
+	return Vec3{-v[0], -v[1], -v[2]}
+}
+
+// CubeFace is one face of the cube net, where `NetRow` and `NetCol` are its position
+// in the net, measured in faces. `Right` and `Down` are the 3D directions of the face's
+// columns and rows once folded, and `Normal` points out of the cube
+type CubeFace struct {
+	NetRow, NetCol      int
+	Right, Down, Normal Vec3
+}
+
+// Axis returns the 3D direction of travel when facing `dir` on the face
+func (f *CubeFace) Axis(dir Facing) Vec3 {
+	switch dir {
+	case RIGHT:
+		return f.Right
+	case DOWN:
+		return f.Down
+	case LEFT:
+		return f.Right.Neg()
+	case UP:
+		return f.Down.Neg()
+	}
+	panic("Invalid direction")
+}
+
+// FacingAlong returns the facing on the face that travels in the 3D direction `v`
+func (f *CubeFace) FacingAlong(v Vec3) Facing {
+	for dir := Facing(RIGHT); dir <= UP; dir++ {
+		if f.Axis(dir) == v {
+			return dir
+		}
+	}
+	panic(fmt.Sprintf("%v doesn't lie on face (%d, %d)", v, f.NetRow, f.NetCol))
+}
+
+// Fold rolls the face over its edge in direction `dir`, returning the frame
+// of the face that's next to it in that direction
+func (f *CubeFace) Fold(dir Facing) CubeFace {
+	next := *f
+	switch dir {
+	case RIGHT:
+		next.NetCol++
+		next.Normal, next.Right = f.Right, f.Normal.Neg()
+	case LEFT:
+		next.NetCol--
+		next.Normal, next.Right = f.Right.Neg(), f.Normal
+	case DOWN:
+		next.NetRow++
+		next.Normal, next.Down = f.Down, f.Normal.Neg()
+	case UP:
+		next.NetRow--
+		next.Normal, next.Down = f.Down.Neg(), f.Normal
+	}
+	return next
+}
+
+// getFaceSize works out the length of a cube face's side from the number of tiles
+func getFaceSize(board [][]*Tile) int {
+	count := 0
+	for _, row := range board {
+		for _, t := range row {
+			if t != nil {
+				count++
+			}
+		}
+	}
+
+	size := 1
+	for 6*size*size < count {
+		size++
+	}
+	if 6*size*size != count {
+		panic(fmt.Sprintf("%d tiles can't be split into 6 square faces", count))
+	}
+	return size
+}
+
+// FoldCube finds the faces of the cube net on the board, and folds them together,
+// starting from the face with the top left tile
+func FoldCube(board [][]*Tile, size int) []CubeFace {
+	hasFace := func(netRow, netCol int) bool {
+		row, col := netRow*size, netCol*size
+		return netRow >= 0 && netCol >= 0 && row < len(board) && col < len(board[row]) && board[row][col] != nil
+	}
+
+	if len(board) == 0 {
+		panic("board is empty")
+	}
+
+	// Start folding from the left-most face in the first row of faces
+	start, found := CubeFace{}, false
+	for netCol := 0; netCol < len(board[0])/size; netCol++ {
+		if hasFace(0, netCol) {
+			start, found = CubeFace{0, netCol, Vec3{1, 0, 0}, Vec3{0, 1, 0}, Vec3{0, 0, -1}}, true
+			break
+		}
+	}
+	if !found {
+		panic(fmt.Sprintf("board has no %dx%d face in its first row of faces", size, size))
+	}
+
+	// Walk across the net, folding each new face over the edge it was reached from
+	faces := []CubeFace{start}
+	seen := map[[2]int]bool{{start.NetRow, start.NetCol}: true}
+	toProcess := ds.Queue[CubeFace]{start}
+	for !toProcess.IsEmpty() {
+		face, _ := toProcess.Dequeue()
+		for dir := Facing(RIGHT); dir <= UP; dir++ {
+			next := face.Fold(dir)
+			pos := [2]int{next.NetRow, next.NetCol}
+			if seen[pos] || !hasFace(next.NetRow, next.NetCol) {
+				continue
+			}
+			seen[pos] = true
+			faces = append(faces, next)
+			toProcess.Enqueue(next)
+		}
+	}
+
+	normals := ds.NewSet(util.Map(faces, func(f CubeFace) Vec3 { return f.Normal })...)
+	if len(faces) != 6 || normals.Len() != 6 {
+		panic(fmt.Sprintf("board isn't a cube net with %dx%d faces", size, size))
+	}
+	return faces
+}
+
+// getEdgeTile returns the board position of the tile at `offset` along the edge
+// of `f` that's crossed when leaving it in direction `dir`
+func getEdgeTile(f CubeFace, dir Facing, offset, size int) (int, int) {
+	row, col := f.NetRow*size, f.NetCol*size
+	switch dir {
+	case RIGHT:
+		return row + offset, col + size - 1
+	case LEFT:
+		return row + offset, col
+	case DOWN:
+		return row + size - 1, col + offset
+	case UP:
+		return row, col + offset
+	}
+	panic("Invalid direction")
+}
+
+// getCubeRemapping remaps the direction from a tile to a new tile/direction,
+// such that the map connects like a cube net. The face size and the layout of
+// the net are worked out from the board, so any of the cube nets is supported
 func getCubeRemapping(board [][]*Tile) [][]map[Facing]Remap {
 	remaps := make([][]map[Facing]Remap, len(board))
 	for i := range remaps {
@@ -241,39 +489,51 @@ func getCubeRemapping(board [][]*Tile) [][]map[Facing]Remap {
 		}
 	}
 
-	for i := 0; i < 50; i++ {
-		// 1 <-> 4 (4 rotated 180 degrees left of 1)
-		remaps[i][50][LEFT] = Remap{board[149-i][0], RIGHT}
-		remaps[149-i][0][LEFT] = Remap{board[i][50], RIGHT}
-
-		// 1 <-> 6 (6 rotated 90 degrees CCW, and above 1)
-		remaps[0][50+i][UP] = Remap{board[150+i][0], RIGHT}
-		remaps[150+i][0][LEFT] = Remap{board[0][50+i], DOWN}
-
-		// 2 <-> 6 (6 above 2 directly)
-		remaps[0][100+i][UP] = Remap{board[199][i], UP}
-		remaps[199][i][DOWN] = Remap{board[0][100+i], DOWN}
-
-		// 2 <-> 3 (3 rotated 90 degrees CCW, below 2)
-		remaps[49][100+i][DOWN] = Remap{board[50+i][99], LEFT}
-		remaps[50+i][99][RIGHT] = Remap{board[49][100+i], UP}
-
-		// 2 <-> 5 (5 rotated 180 degrees, right of 2)
-		remaps[i][149][RIGHT] = Remap{board[149-i][99], LEFT}
-		remaps[149-i][99][RIGHT] = Remap{board[i][149], LEFT}
-
-		// 3 <-> 4 (4 rotated 90 degrees CW, left of 3)
-		remaps[50+i][50][LEFT] = Remap{board[100][i], DOWN}
-		remaps[100][i][UP] = Remap{board[50+i][50], RIGHT}
+	size := getFaceSize(board)
+	faces := FoldCube(board, size)
+	byNormal := map[Vec3]CubeFace{}
+	for _, f := range faces {
+		byNormal[f.Normal] = f
+	}
 
-		// 5 <-> 6 (6 rotated 90 degrees CCW, below 5)
-		remaps[149][50+i][DOWN] = Remap{board[150+i][49], LEFT}
-		remaps[150+i][49][RIGHT] = Remap{board[149][50+i], UP}
+	for _, from := range faces {
+		for dir := Facing(RIGHT); dir <= UP; dir++ {
+			// Leaving in direction `dir` leads to the face pointing that way,
+			// and then heads away from the face we came from
+			to := byNormal[from.Axis(dir)]
+			if next := from.Fold(dir); to.NetRow == next.NetRow && to.NetCol == next.NetCol {
+				continue // Already next to each other in the net, so no remap needed
+			}
+			newDir := to.FacingAlong(from.Normal.Neg())
+
+			// Tiles along the edge are matched up by their 3D position along it,
+			// which is the same axis on both faces but may run the opposite way
+			reversed := to.Axis(edgeAxis(newDir)) != from.Axis(edgeAxis(dir))
+			for offset := 0; offset < size; offset++ {
+				toOffset := offset
+				if reversed {
+					toOffset = size - 1 - offset
+				}
+
+				fromRow, fromCol := getEdgeTile(from, dir, offset, size)
+				toRow, toCol := getEdgeTile(to, (newDir+2)%4, toOffset, size)
+				remaps[fromRow][fromCol][dir] = Remap{board[toRow][toCol], newDir}
+			}
+		}
 	}
 
 	return remaps
 }
 
+// edgeAxis is the direction that tiles are numbered in along the edge crossed
+// when travelling in direction `dir`
+func edgeAxis(dir Facing) Facing {
+	if dir == RIGHT || dir == LEFT {
+		return DOWN
+	}
+	return RIGHT
+}
+
 func walkWithRemap(curTile *Tile, curDir Facing, remaps [][]map[Facing]Remap) (*Tile, bool, Facing) {
 	defaultWalk := func() (*Tile, bool, Facing) {
 		newTile, walked := curTile.Walk(curDir)
@@ -300,25 +560,19 @@ func walkWithRemap(curTile *Tile, curDir Facing, remaps [][]map[Facing]Remap) (*
 
 func PartTwo() any {
 	board, instructions := getPartOneData()
-	curTile := getStartingTile(board)
-	remaps := getCubeRemapping(board)
-	var curDir Facing = RIGHT
+	trace := os.Getenv(TraceEnvVar) == "1"
 
-	for _, instruction := range instructions {
-		if instruction.IsTurn {
-			curDir = instruction.TurnDir(curDir)
-			continue
-		}
-		for i := 0; i < instruction.Steps; i++ {
-			var walked bool
-			curTile, walked, curDir = walkWithRemap(curTile, curDir, remaps)
-			if !walked {
-				break
-			}
-		}
+	remaps := getCubeRemapping(board)
+	walk := func(curTile *Tile, curDir Facing) (*Tile, bool, Facing) {
+		return walkWithRemap(curTile, curDir, remaps)
 	}
+	curTile, curDir, path := FollowPath(board, instructions, walk, trace)
 
-	return curTile.Row*1000 + curTile.Col*4 + int(curDir)
+	if trace {
+		log.Infow("Walked route\n"+RenderPath(board, path), "part", 2)
+		log.Infow("Walked route by face\n"+RenderFaces(board, path), "part", 2)
+	}
+	return getPassword(curTile, curDir)
 }
 
 func main() {
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+var exampleLines = strings.Split(`        ...#
+        .#..
+        #...
+        ....
+...#.......#
+........#...
+..#....#....
+..........#.
+        ...#....
+        .....#..
+        .#......
+        ......#.
+
+10R5L5R10L4R5L5`, "\n")
+
+func TestCubeExample(t *testing.T) {
+	board, instructions := parseInput(exampleLines)
+	remaps := getCubeRemapping(board)
+	walk := func(curTile *Tile, curDir Facing) (*Tile, bool, Facing) {
+		return walkWithRemap(curTile, curDir, remaps)
+	}
+
+	curTile, curDir, _ := FollowPath(board, instructions, walk, false)
+	if got := getPassword(curTile, curDir); got != 5031 {
+		t.Errorf("password = %d, want 5031", got)
+	}
+}
+
+func TestFoldCubeNoFaceInFirstRow(t *testing.T) {
+	// A 4x4 board of 2x2 faces, where the first row of faces is empty
+	board := make([][]*Tile, 4)
+	for i := range board {
+		board[i] = make([]*Tile, 4)
+	}
+	board[2][0] = &Tile{Type: '.'}
+
+	done := make(chan any)
+	go func() {
+		defer func() { done <- recover() }()
+		FoldCube(board, 2)
+	}()
+
+	select {
+	case r := <-done:
+		if r == nil {
+			t.Error("FoldCube didn't panic")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("FoldCube didn't return")
+	}
+}
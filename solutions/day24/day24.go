@@ -7,7 +7,6 @@ import (
 	ds "github.com/ShajeshJ/adventofcode_2022/common/datastructures"
 	"github.com/ShajeshJ/adventofcode_2022/common/logging"
 	"github.com/ShajeshJ/adventofcode_2022/common/util"
-	"golang.org/x/exp/slices"
 )
 
 var log = logging.GetLogger()
@@ -37,10 +36,29 @@ type SimState struct {
 	StartPos  Position
 	EndPos    Position
 	blizzards []Blizzard
+	occupied  *ds.BitSet // Cells with at least 1 blizzard in them, by `CellIndex`
 	h, w      int
 	maxCycles int
 }
 
+// NumCells returns the number of cells in the valley, including the row with
+// the start and the row with the end
+func (ss *SimState) NumCells() int {
+	return (ss.h + 2) * ss.w
+}
+
+// CellIndex returns a unique index for the cell at `p`, in the range [0, NumCells)
+func (ss *SimState) CellIndex(p Position) int {
+	return (p.Y+1)*ss.w + p.X
+}
+
+func (ss *SimState) UpdateOccupied() {
+	ss.occupied.Reset()
+	for _, b := range ss.blizzards {
+		ss.occupied.Set(ss.CellIndex(b.Position))
+	}
+}
+
 func (ss *SimState) UpdateBlizzards() {
 	for i := 0; i < len(ss.blizzards); i++ {
 		switch ss.blizzards[i].Dir {
@@ -54,6 +72,7 @@ func (ss *SimState) UpdateBlizzards() {
 			ss.blizzards[i].X = (ss.blizzards[i].X + 1) % ss.w
 		}
 	}
+	ss.UpdateOccupied()
 }
 
 func (ss *SimState) GetValidMoves(p Position) []Position {
@@ -101,7 +120,7 @@ func getPartOneData() SimState {
 		}
 	}
 
-	return SimState{
+	ss := SimState{
 		StartPos:  start,
 		EndPos:    end,
 		blizzards: blizzards,
@@ -109,17 +128,19 @@ func getPartOneData() SimState {
 		w:         w,
 		maxCycles: util.LCM(h, w),
 	}
-}
-
-type RepeatState struct {
-	x, y, cycle int
+	ss.occupied = ds.NewFixedBitSet(ss.NumCells())
+	ss.UpdateOccupied()
+	return ss
 }
 
 func FindMinTravelTime(ss *SimState) int {
 	q := ds.Queue[Position]{ss.StartPos}
 	timeTaken := 0
 	curCycle := 0
-	visited := make(map[RepeatState]bool)
+
+	// Blizzards repeat every `maxCycles` minutes, so a state is identified by the
+	// cell and the cycle; each cycle gets its own block of bits
+	visited := ds.NewFixedBitSet(ss.maxCycles * ss.NumCells())
 
 	for {
 		nextQ := ds.Queue[Position]{}
@@ -130,24 +151,22 @@ func FindMinTravelTime(ss *SimState) int {
 
 		for !q.IsEmpty() {
 			p, _ := q.Dequeue()
-			if visited[RepeatState{p.X, p.Y, curCycle}] {
+			state := curCycle*ss.NumCells() + ss.CellIndex(p)
+			if visited.Test(state) {
 				continue
 			}
 			for _, move := range ss.GetValidMoves(p) {
 				if move == ss.EndPos {
 					return timeTaken
 				}
-				if blizzard := slices.IndexFunc(
-					ss.blizzards,
-					func(b Blizzard) bool { return b.Position == move },
-				); blizzard != -1 {
+				if ss.occupied.Test(ss.CellIndex(move)) {
 					// Blizzard in the way, can't move here
 					continue
 				}
 
 				nextQ.Enqueue(move)
 			}
-			visited[RepeatState{p.X, p.Y, curCycle}] = true
+			visited.Set(state)
 		}
 
 		q = nextQ
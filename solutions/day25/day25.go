@@ -3,8 +3,10 @@ package main
 import (
 	"embed"
 	"fmt"
+	"math/big"
 
 	"github.com/ShajeshJ/adventofcode_2022/common/logging"
+	"github.com/ShajeshJ/adventofcode_2022/common/num"
 	"github.com/ShajeshJ/adventofcode_2022/common/util"
 	"golang.org/x/exp/slices"
 )
@@ -22,10 +24,16 @@ const OFFSET = 2 // The negative offset of a digit's actual value to its index i
 
 type Snafu []SNAFUDIGIT
 
-func (s *Snafu) Int() int {
-	val := 0
-	for i, r := range *s {
-		val += util.Pow(5, i) * (slices.Index(DIGITS, r) - OFFSET)
+// Value converts `s` into a number using `a`
+func Value[T any](a num.Arith[T], s Snafu) T {
+	val, place, base := a.FromInt(0), a.FromInt(1), a.FromInt(5)
+	for i, r := range s {
+		digit := a.FromInt(slices.Index(DIGITS, r) - OFFSET)
+		val = num.Must(a.Add(val, num.Must(a.Mul(place, digit))))
+		if i < len(s)-1 {
+			// The place after the last digit isn't needed, and may not fit even if `val` does
+			place = num.Must(a.Mul(place, base))
+		}
 	}
 	return val
 }
@@ -38,8 +46,7 @@ func (s *Snafu) String() string {
 	return str
 }
 
-func GetNextDigit(n int) (SNAFUDIGIT, int) {
-	rem := n % 5
+func GetNextDigit(rem int) (SNAFUDIGIT, int) {
 	carry := 0
 
 	if rem > 2 {
@@ -50,12 +57,16 @@ func GetNextDigit(n int) (SNAFUDIGIT, int) {
 	return DIGITS[rem+OFFSET], carry
 }
 
-func ConvertToSnafu(n int) Snafu {
+// ConvertToSnafu converts the non-negative number `n` into SNAFU, using `a`
+func ConvertToSnafu[T any](a num.Arith[T], n T) Snafu {
 	snafu := make(Snafu, 0)
-	for n > 0 {
-		digit, carry := GetNextDigit(n)
+	zero, base := a.FromInt(0), a.FromInt(5)
+	for a.Cmp(n, zero) > 0 {
+		// The remainder is a single base 5 digit, so it always fits in an int
+		rem := util.AtoiNoError(a.String(num.Must(a.Mod(n, base))))
+		digit, carry := GetNextDigit(rem)
 		snafu = append(snafu, digit)
-		n = n/5 + carry
+		n = num.Must(a.Add(num.Must(a.FloorDiv(n, base)), a.FromInt(carry)))
 	}
 	return snafu
 }
@@ -72,13 +83,17 @@ func getPartOneData() []Snafu {
 	return snafus
 }
 
+func partOne[T any](a num.Arith[T]) string {
+	values := util.Map(getPartOneData(), func(s Snafu) T { return Value(a, s) })
+	totalAsSnafu := ConvertToSnafu(a, num.Must(num.Sum(a, values)))
+	return totalAsSnafu.String()
+}
+
 func PartOne() any {
-	total := 0
-	for _, snafu := range getPartOneData() {
-		total += snafu.Int()
+	if num.UseBig() {
+		return partOne[*big.Int](num.Big{})
 	}
-	totalAsSnafu := ConvertToSnafu(total)
-	return totalAsSnafu.String()
+	return partOne[int](num.Int{})
 }
 
 func PartTwo() any {
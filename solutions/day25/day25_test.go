@@ -0,0 +1,50 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ShajeshJ/adventofcode_2022/common/num"
+)
+
+// parseSnafu reads a SNAFU number written most significant digit first
+func parseSnafu(str string) Snafu {
+	snafu := make(Snafu, 0, len(str))
+	for i := len(str) - 1; i >= 0; i-- {
+		snafu = append(snafu, SNAFUDIGIT(str[i]))
+	}
+	return snafu
+}
+
+func TestValue(t *testing.T) {
+	tests := []struct {
+		snafu string
+		want  string
+	}{
+		{"1", "1"},
+		{"1=", "3"},
+		{"2=-01", "976"},
+		{"1-0---0", "12345"},
+		{"1121-1110-1=0", "314159265"},
+		// 28 digits, where the values fit in an int but 5^28 doesn't
+		{"1===========================", "3725290298461914063"},
+		{"1000000000000000000000000000", "7450580596923828125"},
+	}
+
+	intArith, bigArith := num.Int{}, num.Big{}
+	for _, tt := range tests {
+		snafu := parseSnafu(tt.snafu)
+		if got := intArith.String(Value[int](intArith, snafu)); got != tt.want {
+			t.Errorf("Value[int](%v) = %v, want %v", tt.snafu, got, tt.want)
+		}
+		if got := bigArith.String(Value[*big.Int](bigArith, snafu)); got != tt.want {
+			t.Errorf("Value[big](%v) = %v, want %v", tt.snafu, got, tt.want)
+		}
+
+		// Converting back should give the same digits
+		back := ConvertToSnafu[int](intArith, Value[int](intArith, snafu))
+		if got := back.String(); got != tt.snafu {
+			t.Errorf("ConvertToSnafu(Value(%v)) = %v", tt.snafu, got)
+		}
+	}
+}
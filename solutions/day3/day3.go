@@ -4,6 +4,7 @@ import (
 	"embed"
 	"fmt"
 
+	ds "github.com/ShajeshJ/adventofcode_2022/common/datastructures"
 	"github.com/ShajeshJ/adventofcode_2022/common/logging"
 	"github.com/ShajeshJ/adventofcode_2022/common/util"
 )
@@ -13,29 +14,20 @@ var log = logging.GetLogger()
 //go:embed input.txt
 var files embed.FS
 
-func getPartTwoData() (data [][]string) {
-	rucksacks := util.ReadProblemInput(files)
-
-	for i := 0; i < len(rucksacks); i += 3 {
-		data = append(data, []string{rucksacks[i], rucksacks[i+1], rucksacks[i+2]})
-	}
-
-	return
+func getPartTwoData() [][]string {
+	return util.Chunk(util.ReadProblemInput(files), 3)
 }
 
+// getCommonLetter returns the letter found in every one of `strs`. Each string is
+// turned into a set first, so a letter repeated within one string isn't mistaken
+// for a letter shared with the others
 func getCommonLetter(strs ...string) rune {
-	overlapCount := map[rune]int{}
-
-	for _, str := range strs[:len(strs)-1] {
-		for _, r := range str {
-			overlapCount[r]++
-		}
-	}
+	sets := util.Map(strs, func(s string) ds.Set[rune] {
+		return ds.NewSet([]rune(s)...)
+	})
 
-	for _, r := range strs[len(strs)-1] {
-		if overlapCount[r] == len(strs)-1 {
-			return r
-		}
+	for r := range sets[0].Intersection(sets[1:]...) {
+		return r
 	}
 
 	return 0 // Shouldn't happen
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"regexp"
 
+	"github.com/ShajeshJ/adventofcode_2022/common/interval"
 	"github.com/ShajeshJ/adventofcode_2022/common/logging"
 	"github.com/ShajeshJ/adventofcode_2022/common/util"
 )
@@ -14,19 +15,8 @@ var log = logging.GetLogger()
 //go:embed input.txt
 var files embed.FS
 
-type Elf struct {
-	start int
-	end   int
-}
-
-func (e *Elf) Contains(other Elf) bool {
-	return e.start >= other.start && e.end <= other.end
-}
-
-func (e *Elf) Intersects(other Elf) bool {
-	return (e.end >= other.start && e.start <= other.end ||
-		other.end >= e.start && other.start <= e.end)
-}
+// Elf is the range of section IDs an elf is assigned to clean
+type Elf = interval.Interval
 
 var inputRegex = regexp.MustCompile(`(\d+)-(\d+),(\d+)-(\d+)`)
 
@@ -36,7 +26,7 @@ func getPartOneData() (elfPairs [][]Elf) {
 		for _, m := range inputRegex.FindStringSubmatch(line)[1:] {
 			p = append(p, util.AtoiNoError(m))
 		}
-		elfPairs = append(elfPairs, []Elf{{p[0], p[1]}, {p[2], p[3]}})
+		elfPairs = append(elfPairs, []Elf{interval.New(p[0], p[1]), interval.New(p[2], p[3])})
 	}
 	return
 }
@@ -47,7 +37,7 @@ func PartOne() any {
 	total := 0
 
 	for _, pair := range elfPairs {
-		if pair[0].Contains(pair[1]) || pair[1].Contains(pair[0]) {
+		if pair[0].ContainsInterval(pair[1]) || pair[1].ContainsInterval(pair[0]) {
 			total += 1
 		}
 	}
@@ -61,7 +51,7 @@ func PartTwo() any {
 	total := 0
 
 	for _, pair := range elfPairs {
-		if pair[0].Intersects(pair[1]) {
+		if pair[0].Overlaps(pair[1]) {
 			total += 1
 		}
 	}
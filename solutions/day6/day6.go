@@ -3,8 +3,8 @@ package main
 import (
 	"embed"
 	"fmt"
-	"strings"
 
+	ds "github.com/ShajeshJ/adventofcode_2022/common/datastructures"
 	"github.com/ShajeshJ/adventofcode_2022/common/logging"
 	"github.com/ShajeshJ/adventofcode_2022/common/util"
 )
@@ -14,32 +14,21 @@ var log = logging.GetLogger()
 //go:embed input.txt
 var files embed.FS
 
-func hasDuplicateRunes(s string) bool {
-	for _, c := range s {
-		if strings.Count(s, string(c)) > 1 {
-			return true
-		}
-	}
-	return false
+func hasDuplicateRunes(runes []rune) bool {
+	return ds.NewSet(runes...).Len() != len(runes)
 }
 
 func PartOne(n int) any {
-	seq := util.ReadProblemInput(files)[0]
-
-	// Must be a minimum of n characters long
-	buffer := seq[:n]
-	counter := n
+	seq := []rune(util.ReadProblemInput(files)[0])
 
-	for _, c := range seq[n:] {
+	// The marker ends at the last character of the first window without duplicates
+	for i, buffer := range util.SlidingWindow(seq, n) {
 		if !hasDuplicateRunes(buffer) {
-			break
+			return i + n
 		}
-		buffer = buffer[1:]
-		buffer += string(c)
-		counter++
 	}
 
-	return counter
+	return len(seq)
 }
 
 func PartTwo() any {